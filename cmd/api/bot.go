@@ -0,0 +1,680 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/adapter/telegram"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/internal/usecase"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// telegramWebhookPath is where Telegram delivers updates in webhook mode.
+const telegramWebhookPath = "/api/v1/telegram/webhook"
+
+const botHelp = `<b>ReleaseRadar</b> posts GitHub releases to this chat.
+
+/watch <code>owner/repo</code> – notify about new releases
+/unwatch <code>owner/repo</code> – stop notifications
+/list – repos watched in this chat
+/filters <code>owner/repo</code> [updates|deletions|assets|majors|topics on|off] – show or change what is sent
+/digest <code>owner/repo</code> [instant|hourly|daily <i>HH:MM</i>|weekly <i>day HH:MM</i>] – show or change how often notifications arrive
+/timezone [<code>Area/City</code>] – show or set the timezone of digests and quiet hours
+/quiet [<i>HH:MM HH:MM</i> [bundle] [security]|off] – show, set or remove quiet hours
+
+Commands sent in a forum topic manage the subscriptions of that topic.`
+
+var repoArgPattern = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)/([A-Za-z0-9._-]+)$`)
+
+// muteDuration is how long the "Mute" button on notifications silences a subscription.
+const muteDuration = 7 * 24 * time.Hour
+
+// telegramBot answers bot commands, letting each chat manage its own subscriptions, and the
+// buttons on notifications. A chat acts as a user of its own, created on its first command.
+type telegramBot struct {
+	client         telegram.Client
+	users          usecase.UserUseCase
+	repos          usecase.RepoUseCase
+	subs           usecase.SubscriptionUseCase
+	releases       usecase.ReleaseUseCase
+	callbackSecret []byte
+}
+
+func newTelegramBot(client telegram.Client, users usecase.UserUseCase, repos usecase.RepoUseCase, subs usecase.SubscriptionUseCase, releases usecase.ReleaseUseCase, callbackSecret []byte) *telegramBot {
+	return &telegramBot{client: client, users: users, repos: repos, subs: subs, releases: releases, callbackSecret: callbackSecret}
+}
+
+// handleUpdate dispatches a command or button press.
+func (b *telegramBot) handleUpdate(ctx context.Context, update telegram.Update) {
+	switch {
+	case update.Command != nil:
+		b.handle(ctx, *update.Command)
+	case update.Callback != nil:
+		b.handleCallback(ctx, *update.Callback)
+	}
+}
+
+// handle runs a command and posts the reply to the chat it came from.
+func (b *telegramBot) handle(ctx context.Context, cmd telegram.Command) {
+	reply, err := b.run(ctx, cmd)
+	if err != nil {
+		logger.L().Sugar().Errorf("telegram command /%s in chat %d failed: %v", cmd.Name, cmd.ChatID, err)
+		reply = "Something went wrong, please try again later."
+	}
+	if reply == "" {
+		return
+	}
+	if err := b.client.SendMessage(ctx, chatAddress(cmd.ChatID, cmd.ThreadID), reply); err != nil {
+		logger.L().Sugar().Errorf("failed to reply to telegram command /%s in chat %d: %v", cmd.Name, cmd.ChatID, err)
+	}
+}
+
+// handleCallback performs the action of a notification button, answers the press with a
+// short notice and, for actions on the subscription, replaces the buttons with a confirmation.
+func (b *telegramBot) handleCallback(ctx context.Context, cb telegram.Callback) {
+	notice, confirmation, err := b.callback(ctx, cb)
+	if err != nil {
+		logger.L().Sugar().Errorf("telegram callback in chat %d failed: %v", cb.ChatID, err)
+		notice, confirmation = "Something went wrong, please try again later.", ""
+	}
+
+	if err := b.client.AnswerCallback(ctx, cb.ID, notice); err != nil {
+		logger.L().Sugar().Errorf("failed to answer telegram callback in chat %d: %v", cb.ChatID, err)
+	}
+	if confirmation == "" {
+		return
+	}
+	buttons := [][]telegram.Button{{{Text: confirmation, Data: telegram.NoopCallback}}}
+	if err := b.client.EditButtons(ctx, strconv.FormatInt(cb.ChatID, 10), cb.MessageID, buttons); err != nil {
+		logger.L().Sugar().Errorf("failed to confirm telegram callback in chat %d: %v", cb.ChatID, err)
+	}
+}
+
+// callback returns the notice to show for a button press and the confirmation to put in place
+// of the buttons, if any.
+func (b *telegramBot) callback(ctx context.Context, cb telegram.Callback) (notice, confirmation string, err error) {
+	if cb.Data == telegram.NoopCallback {
+		return "", "", nil
+	}
+	action, id, err := telegram.ParseCallbackData(b.callbackSecret, cb.Data)
+	if err != nil {
+		return "This button is no longer valid.", "", nil
+	}
+	if action == telegram.ActionFullNotes {
+		release, err := b.releases.GetRelease(ctx, id)
+		if errors.Is(err, persistence.ErrNotFound) {
+			return "This release is no longer available.", "", nil
+		}
+		if err != nil {
+			return "", "", err
+		}
+		repo, err := b.repos.GetRepoByID(ctx, release.RepoID)
+		if err != nil {
+			return "", "", err
+		}
+		return "", "", telegram.SendNotes(ctx, b.client, chatAddress(cb.ChatID, cb.ThreadID), repo, release)
+	}
+
+	// Buttons can only change subscriptions of the chat they were sent to
+	sub, err := b.subs.GetSubscription(ctx, id)
+	if errors.Is(err, persistence.ErrNotFound) || (err == nil && !inChat(sub, cb.ChatID)) {
+		return "This subscription no longer exists.", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	switch action {
+	case telegram.ActionMute:
+		until := time.Now().Add(muteDuration)
+		if _, err := b.subs.Mute(ctx, sub.ID, until); err != nil {
+			return "", "", err
+		}
+		return "Muted for 7 days.", "🔕 Muted until " + until.UTC().Format("2006-01-02"), nil
+	case telegram.ActionMajorOnly:
+		if _, err := b.subs.SetMajorOnly(ctx, sub.ID, true); err != nil {
+			return "", "", err
+		}
+		return "Only major releases from now on.", "✓ Only major releases", nil
+	case telegram.ActionUnsubscribe:
+		if _, err := b.subs.UnsubscribeByID(ctx, sub.ID); err != nil {
+			return "", "", err
+		}
+		return "Unsubscribed.", "✓ Unsubscribed", nil
+	}
+	return "This button is no longer valid.", "", nil
+}
+
+func (b *telegramBot) run(ctx context.Context, cmd telegram.Command) (string, error) {
+	switch cmd.Name {
+	case "start", "help":
+		if _, err := b.users.GetOrCreateTelegramUser(ctx, cmd.ChatID); err != nil {
+			return "", err
+		}
+		return botHelp, nil
+	case "watch":
+		return b.watch(ctx, cmd)
+	case "unwatch":
+		return b.unwatch(ctx, cmd)
+	case "list":
+		return b.list(ctx, cmd)
+	case "filters":
+		return b.filters(ctx, cmd)
+	case "digest":
+		return b.digest(ctx, cmd)
+	case "timezone":
+		return b.timezone(ctx, cmd)
+	case "quiet":
+		return b.quiet(ctx, cmd)
+	}
+
+	// Groups share commands between bots, so only answer unknown commands in private chats
+	if cmd.ChatType == "private" {
+		return "Unknown command.\n\n" + botHelp, nil
+	}
+	return "", nil
+}
+
+func (b *telegramBot) watch(ctx context.Context, cmd telegram.Command) (string, error) {
+	owner, name, ok := parseRepoArg(cmd.Args)
+	if !ok {
+		return "Usage: /watch <code>owner/repo</code>", nil
+	}
+
+	user, err := b.users.GetOrCreateTelegramUser(ctx, cmd.ChatID)
+	if err != nil {
+		return "", err
+	}
+	repo, err := b.repos.FindOrAddRepo(ctx, user.ID, owner, name)
+	if errors.Is(err, persistence.ErrNotFound) {
+		return fmt.Sprintf("Repository <b>%s</b> was not found on GitHub.", html.EscapeString(owner+"/"+name)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_, err = b.subs.Subscribe(ctx, user.ID, repo.ID, chatChannel(cmd.ChatID, cmd.ThreadID))
+	if errors.Is(err, usecase.ErrAlreadyExists) {
+		return fmt.Sprintf("Already watching <b>%s</b>.", html.EscapeString(repoName(repo))), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Watching <b>%s</b>. New releases will be posted here.", html.EscapeString(repoName(repo))), nil
+}
+
+func (b *telegramBot) unwatch(ctx context.Context, cmd telegram.Command) (string, error) {
+	owner, name, ok := parseRepoArg(cmd.Args)
+	if !ok {
+		return "Usage: /unwatch <code>owner/repo</code>", nil
+	}
+	notWatching := fmt.Sprintf("<b>%s</b> is not watched in this chat.", html.EscapeString(owner+"/"+name))
+
+	user, repo, err := b.chatRepo(ctx, cmd.ChatID, owner, name)
+	if err != nil {
+		return "", err
+	}
+	if repo == nil {
+		return notWatching, nil
+	}
+
+	err = b.subs.Unsubscribe(ctx, user.ID, repo.ID, chatChannel(cmd.ChatID, cmd.ThreadID))
+	if errors.Is(err, persistence.ErrNotFound) {
+		return notWatching, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Stopped watching <b>%s</b>.", html.EscapeString(repoName(repo))), nil
+}
+
+func (b *telegramBot) list(ctx context.Context, cmd telegram.Command) (string, error) {
+	user, err := b.users.GetOrCreateTelegramUser(ctx, cmd.ChatID)
+	if err != nil {
+		return "", err
+	}
+	subs, err := b.subs.ListSubscriptions(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// The whole chat is listed, including subscriptions made in forum topics
+	var lines []string
+	for _, sub := range subs {
+		if !inChat(&sub, cmd.ChatID) {
+			continue
+		}
+		repo, err := b.repos.GetRepoByID(ctx, sub.RepoID)
+		if err != nil {
+			return "", err
+		}
+		if repo == nil {
+			continue
+		}
+		line := "• " + html.EscapeString(repoName(repo))
+		if flags := subscriptionFlags(&sub); flags != "" {
+			line += " <i>(" + flags + ")</i>"
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "No repos are watched in this chat yet. Add one with /watch <code>owner/repo</code>.", nil
+	}
+	return "<b>Watched repos</b>\n" + strings.Join(lines, "\n"), nil
+}
+
+// filters shows the notification settings of a watched repo, or changes them from
+// "<setting> on|off" pairs, e.g. "/filters owner/repo updates on assets off".
+func (b *telegramBot) filters(ctx context.Context, cmd telegram.Command) (string, error) {
+	usage := "Usage: /filters <code>owner/repo</code> [updates|deletions|assets|majors|topics on|off]..."
+	fields := strings.Fields(cmd.Args)
+	if len(fields) == 0 || len(fields)%2 == 0 {
+		return usage, nil
+	}
+	owner, name, ok := parseRepoArg(fields[0])
+	if !ok {
+		return usage, nil
+	}
+	notWatching := fmt.Sprintf("<b>%s</b> is not watched in this chat.", html.EscapeString(owner+"/"+name))
+
+	user, repo, err := b.chatRepo(ctx, cmd.ChatID, owner, name)
+	if err != nil {
+		return "", err
+	}
+	if repo == nil {
+		return notWatching, nil
+	}
+	channel := chatChannel(cmd.ChatID, cmd.ThreadID)
+	sub, err := b.findSubscription(ctx, user, repo, channel)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return notWatching, nil
+	}
+
+	onUpdate, onDelete, assets, majors, topics := sub.NotifyOnUpdate, sub.NotifyOnDelete, sub.IncludeAssets, sub.MajorOnly, sub.RepoTopics
+	for i := 1; i < len(fields); i += 2 {
+		var value bool
+		switch strings.ToLower(fields[i+1]) {
+		case "on":
+			value = true
+		case "off":
+			value = false
+		default:
+			return usage, nil
+		}
+		switch strings.ToLower(fields[i]) {
+		case "updates":
+			onUpdate = value
+		case "deletions":
+			onDelete = value
+		case "assets":
+			assets = value
+		case "majors":
+			majors = value
+		case "topics":
+			topics = value
+		default:
+			return usage, nil
+		}
+	}
+
+	if onUpdate != sub.NotifyOnUpdate || onDelete != sub.NotifyOnDelete {
+		if sub, err = b.subs.SetChangeNotifications(ctx, user.ID, repo.ID, channel, onUpdate, onDelete); err != nil {
+			return "", err
+		}
+	}
+	if assets != sub.IncludeAssets {
+		if sub, err = b.subs.SetIncludeAssets(ctx, user.ID, repo.ID, channel, assets); err != nil {
+			return "", err
+		}
+	}
+	if majors != sub.MajorOnly {
+		if sub, err = b.subs.SetMajorOnly(ctx, sub.ID, majors); err != nil {
+			return "", err
+		}
+	}
+	if topics != sub.RepoTopics {
+		if sub, err = b.subs.SetRepoTopics(ctx, sub.ID, topics); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("<b>%s</b>\nEdited release notes: %s\nDeleted releases: %s\nAsset list: %s\nOnly major releases: %s\nForum topic per repo: %s",
+		html.EscapeString(repoName(repo)), onOff(sub.NotifyOnUpdate), onOff(sub.NotifyOnDelete), onOff(sub.IncludeAssets), onOff(sub.MajorOnly), onOff(sub.RepoTopics)), nil
+}
+
+// digest shows or changes the delivery mode of a watched repo, e.g.
+// "/digest owner/repo weekly fri 17:00". Times are in the chat's timezone.
+func (b *telegramBot) digest(ctx context.Context, cmd telegram.Command) (string, error) {
+	usage := "Usage: /digest <code>owner/repo</code> [instant|hourly|daily <i>HH:MM</i>|weekly <i>day HH:MM</i>]"
+	fields := strings.Fields(cmd.Args)
+	if len(fields) == 0 {
+		return usage, nil
+	}
+	owner, name, ok := parseRepoArg(fields[0])
+	if !ok {
+		return usage, nil
+	}
+	notWatching := fmt.Sprintf("<b>%s</b> is not watched in this chat.", html.EscapeString(owner+"/"+name))
+
+	user, repo, err := b.chatRepo(ctx, cmd.ChatID, owner, name)
+	if err != nil {
+		return "", err
+	}
+	if repo == nil {
+		return notWatching, nil
+	}
+	sub, err := b.findSubscription(ctx, user, repo, chatChannel(cmd.ChatID, cmd.ThreadID))
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return notWatching, nil
+	}
+
+	if len(fields) > 1 {
+		mode := strings.ToLower(fields[1])
+		digestTime, weekday := sub.DigestTime, sub.DigestWeekday
+		args := fields[2:]
+		if mode == domain.DeliveryModeWeekly && len(args) > 0 {
+			day, ok := parseWeekday(args[0])
+			if !ok {
+				return usage, nil
+			}
+			weekday, args = int(day), args[1:]
+		}
+		if (mode == domain.DeliveryModeDaily || mode == domain.DeliveryModeWeekly) && len(args) > 0 {
+			digestTime, args = args[0], args[1:]
+		}
+		if len(args) > 0 {
+			return usage, nil
+		}
+
+		sub, err = b.subs.SetDeliveryMode(ctx, sub.ID, mode, digestTime, weekday)
+		if errors.Is(err, usecase.ErrInvalidArgument) {
+			return usage, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return fmt.Sprintf("<b>%s</b>\nDelivery: %s\nTimezone: %s (change with /timezone)",
+		html.EscapeString(repoName(repo)), html.EscapeString(deliveryMode(sub)), html.EscapeString(timezone)), nil
+}
+
+// timezone shows or sets the timezone digests of the chat are scheduled in.
+func (b *telegramBot) timezone(ctx context.Context, cmd telegram.Command) (string, error) {
+	user, err := b.users.GetOrCreateTelegramUser(ctx, cmd.ChatID)
+	if err != nil {
+		return "", err
+	}
+
+	if timezone := strings.TrimSpace(cmd.Args); timezone != "" {
+		user, err = b.users.SetTimezone(ctx, user.ID, timezone)
+		if errors.Is(err, usecase.ErrInvalidArgument) {
+			return fmt.Sprintf("Unknown timezone <code>%s</code>. Use a name like <code>Europe/Berlin</code> or <code>UTC</code>.", html.EscapeString(timezone)), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if user.Timezone == "" {
+		return "Digests and quiet hours are in UTC. Set a timezone with /timezone <code>Area/City</code>.", nil
+	}
+	return fmt.Sprintf("Digests and quiet hours are in <b>%s</b>.", html.EscapeString(user.Timezone)), nil
+}
+
+// quiet shows or changes the quiet hours of the chat, e.g. "/quiet 22:00 07:00 bundle security".
+// "bundle" sends what was held back as one digest, "security" lets security releases through.
+func (b *telegramBot) quiet(ctx context.Context, cmd telegram.Command) (string, error) {
+	usage := "Usage: /quiet <i>HH:MM HH:MM</i> [bundle] [security], or /quiet off"
+	user, err := b.users.GetOrCreateTelegramUser(ctx, cmd.ChatID)
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(strings.ToLower(cmd.Args))
+	switch {
+	case len(fields) == 0:
+		quiet, err := b.users.ListQuietHours(ctx, user.ID)
+		if err != nil {
+			return "", err
+		}
+		if len(quiet) == 0 {
+			return "No quiet hours are set.\n\n" + usage, nil
+		}
+		lines := make([]string, 0, len(quiet))
+		for _, q := range quiet {
+			lines = append(lines, html.EscapeString(quietHours(&q)))
+		}
+		return "Quiet hours:\n" + strings.Join(lines, "\n"), nil
+	case len(fields) == 1 && fields[0] == "off":
+		err := b.users.ClearQuietHours(ctx, user.ID, "")
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			return "", err
+		}
+		return "Quiet hours are off.", nil
+	case len(fields) < 2:
+		return usage, nil
+	}
+
+	quiet := &domain.QuietHours{UserID: user.ID, Start: fields[0], End: fields[1]}
+	for _, option := range fields[2:] {
+		switch option {
+		case "bundle":
+			quiet.Bundle = true
+		case "security":
+			quiet.AllowSecurity = true
+		default:
+			return usage, nil
+		}
+	}
+	quiet, err = b.users.SetQuietHours(ctx, quiet)
+	if errors.Is(err, usecase.ErrInvalidArgument) {
+		return usage, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return fmt.Sprintf("Quiet hours set: %s (%s, change with /timezone).", html.EscapeString(quietHours(quiet)), html.EscapeString(timezone)), nil
+}
+
+// chatRepo returns the user of a chat and the tracked repo owner/name, which is nil if the
+// repo is not tracked at all.
+func (b *telegramBot) chatRepo(ctx context.Context, chatID int64, owner, name string) (*domain.User, *domain.Repo, error) {
+	user, err := b.users.GetOrCreateTelegramUser(ctx, chatID)
+	if err != nil {
+		return nil, nil, err
+	}
+	repo, err := b.repos.GetRepoByName(ctx, owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, repo, nil
+}
+
+func (b *telegramBot) findSubscription(ctx context.Context, user *domain.User, repo *domain.Repo, channel string) (*domain.Subscription, error) {
+	subs, err := b.subs.ListSubscriptions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if subs[i].RepoID == repo.ID && subs[i].Channel == channel {
+			return &subs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// parseRepoArg accepts "owner/repo" as well as a GitHub URL of the repo.
+func parseRepoArg(arg string) (owner, name string, ok bool) {
+	arg = strings.TrimSpace(arg)
+	arg = strings.TrimPrefix(arg, "https://")
+	arg = strings.TrimPrefix(arg, "github.com/")
+	arg = strings.TrimSuffix(strings.TrimSuffix(arg, "/"), ".git")
+
+	match := repoArgPattern.FindStringSubmatch(arg)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// chatAddress is the Telegram address of a chat, or of a forum topic in it.
+func chatAddress(chatID int64, threadID int) string {
+	return telegram.Address(strconv.FormatInt(chatID, 10), threadID)
+}
+
+// chatChannel is the channel that commands sent in a chat or forum topic subscribe.
+func chatChannel(chatID int64, threadID int) string {
+	return domain.ChannelTarget{Type: domain.ChannelTelegram, Address: chatAddress(chatID, threadID)}.String()
+}
+
+// inChat reports whether a subscription posts to the chat, in any of its topics.
+func inChat(sub *domain.Subscription, chatID int64) bool {
+	target, err := domain.ParseChannel(sub.Channel)
+	if err != nil || target.Type != domain.ChannelTelegram {
+		return false
+	}
+	chat, _ := telegram.SplitAddress(target.Address)
+	return chat == strconv.FormatInt(chatID, 10)
+}
+
+func repoName(repo *domain.Repo) string {
+	return repo.Owner + "/" + repo.Name
+}
+
+// subscriptionFlags lists the non-default settings of a subscription for /list.
+func subscriptionFlags(sub *domain.Subscription) string {
+	var flags []string
+	if sub.DisabledAt != nil {
+		flags = append(flags, "disabled")
+	}
+	if sub.MutedUntil != nil && time.Now().Before(*sub.MutedUntil) {
+		flags = append(flags, "muted until "+sub.MutedUntil.UTC().Format("2006-01-02"))
+	}
+	if sub.MajorOnly {
+		flags = append(flags, "majors only")
+	}
+	if target, err := domain.ParseChannel(sub.Channel); err == nil {
+		if _, threadID := telegram.SplitAddress(target.Address); threadID != 0 {
+			flags = append(flags, fmt.Sprintf("topic %d", threadID))
+		}
+	}
+	if sub.RepoTopics {
+		flags = append(flags, "topic per repo")
+	}
+	if sub.IsDigest() {
+		flags = append(flags, deliveryMode(sub))
+	}
+	if sub.NotifyOnUpdate {
+		flags = append(flags, "updates")
+	}
+	if sub.NotifyOnDelete {
+		flags = append(flags, "deletions")
+	}
+	if sub.IncludeAssets {
+		flags = append(flags, "assets")
+	}
+	return strings.Join(flags, ", ")
+}
+
+// deliveryMode describes when a subscription's notifications are sent.
+func deliveryMode(sub *domain.Subscription) string {
+	switch sub.DeliveryMode {
+	case domain.DeliveryModeHourly:
+		return "hourly digest"
+	case domain.DeliveryModeDaily:
+		return "daily digest at " + sub.DigestTime
+	case domain.DeliveryModeWeekly:
+		return fmt.Sprintf("weekly digest on %s at %s", time.Weekday(sub.DigestWeekday), sub.DigestTime)
+	}
+	return "instant"
+}
+
+// quietHours describes a quiet-hours window for /quiet.
+func quietHours(q *domain.QuietHours) string {
+	s := q.Start + "–" + q.End
+	if q.Channel != "" {
+		s = q.Channel + ": " + s
+	}
+	if q.Bundle {
+		s += ", bundled"
+	}
+	if q.AllowSecurity {
+		s += ", security releases allowed"
+	}
+	return s
+}
+
+// parseWeekday accepts English day names and their three-letter abbreviations.
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+// telegramWebhookHandler receives bot updates from Telegram in webhook mode. Requests must
+// carry the secret token the webhook was registered with. Telegram retries anything but a
+// 2xx, so failed commands and button presses are still acknowledged; the chat gets an error
+// notice instead.
+func telegramWebhookHandler(client telegram.Client, bot *telegramBot, secretToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secretToken)) != 1 {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		update, err := client.ParseUpdate(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		bot.handleUpdate(c.Request.Context(), update)
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,615 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/email"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/internal/usecase"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// listReleaseAssetsHandler godoc
+// @Summary List the assets attached to a release
+// @Description Returns name, size, content type, download URL, download count and, when the release publishes a checksums file, the SHA-256 digest of each asset.
+// @Tags releases
+// @Produce json
+// @Param releaseID path string true "Release ID"
+// @Success 200 {array} domain.ReleaseAsset
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/releases/{releaseID}/assets [get]
+func listReleaseAssetsHandler(releases usecase.ReleaseUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		releaseID, err := uuid.Parse(c.Param("releaseID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid release ID"})
+			return
+		}
+
+		assets, err := releases.ListReleaseAssets(c.Request.Context(), releaseID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, assets)
+	}
+}
+
+// respondError maps use case errors to HTTP responses.
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, persistence.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, notify.ErrInvalidTemplate), errors.Is(err, notify.ErrInvalidChannel), errors.Is(err, usecase.ErrInvalidArgument):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, usecase.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	logger.L().Sugar().Errorf("request %s failed: %v", c.Request.URL.Path, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
+
+type subscribeRequest struct {
+	UserID  uuid.UUID `json:"userID" binding:"required"`
+	Channel string    `json:"channel" binding:"required"` // "<type>:<address>", see domain.ParseChannel
+}
+
+// createdSubscription is the response to a new subscription. It is the only response that
+// carries the webhook signing secret; domain.Subscription never serializes it.
+type createdSubscription struct {
+	*domain.Subscription
+	Secret string `json:"secret,omitempty"` // Key of the X-ReleaseRadar-Signature HMAC, webhook channels only
+}
+
+// subscribeHandler godoc
+// @Summary Subscribe a user to a repository's releases on a specific channel
+// @Description For webhook channels the response includes the secret that signs payloads. It is not returned again, including when a disabled subscription is re-enabled by subscribing again.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param repoID path string true "Repository ID"
+// @Param request body subscribeRequest true "User and channel"
+// @Success 200 {object} createdSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/repos/{repoID}/subscribe [post]
+func subscribeHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repoID, err := uuid.Parse(c.Param("repoID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repository ID"})
+			return
+		}
+		var req subscribeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sub, err := subscriptions.Subscribe(c.Request.Context(), req.UserID, repoID, req.Channel)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, createdSubscription{Subscription: sub, Secret: sub.Secret})
+	}
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+{{if .Done}}<p>You have been unsubscribed and will no longer receive these notifications.</p>
+{{else}}<p>Stop receiving release notifications for this subscription?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{end}}</body></html>
+`))
+
+// unsubscribeHandler godoc
+// @Summary Remove a subscription via a signed unsubscribe link
+// @Description GET shows a confirmation page; POST removes the subscription. POST also serves RFC 8058 one-click unsubscribe requests from mail clients.
+// @Tags subscriptions
+// @Produce html
+// @Param token query string true "Signed unsubscribe token from the notification email"
+// @Success 200 {string} string "HTML page"
+// @Failure 400 {string} string "Invalid or expired link"
+// @Failure 404 {string} string "Subscription already removed"
+// @Router /api/v1/unsubscribe [get]
+// @Router /api/v1/unsubscribe [post]
+func unsubscribeHandler(subscriptions usecase.SubscriptionUseCase, secret []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		subscriptionID, err := email.ParseUnsubscribeToken(secret, token)
+		if err != nil {
+			c.String(http.StatusBadRequest, "invalid unsubscribe link")
+			return
+		}
+
+		// Mail clients and link scanners prefetch GET links, so only POST removes anything
+		if c.Request.Method != http.MethodPost {
+			c.Status(http.StatusOK)
+			_ = unsubscribePage.Execute(c.Writer, gin.H{"Token": token})
+			return
+		}
+
+		if _, err := subscriptions.UnsubscribeByID(c.Request.Context(), subscriptionID); err != nil {
+			if errors.Is(err, persistence.ErrNotFound) {
+				c.String(http.StatusNotFound, "subscription already removed")
+				return
+			}
+			logger.L().Sugar().Errorf("request %s failed: %v", c.Request.URL.Path, err)
+			c.String(http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		c.Status(http.StatusOK)
+		_ = unsubscribePage.Execute(c.Writer, gin.H{"Done": true})
+	}
+}
+
+type setTemplateRequest struct {
+	Template string `json:"template"`
+}
+
+// setTemplateHandler godoc
+// @Summary Set the message template of a subscription
+// @Description Validates the template against every notification kind before saving it. An empty template restores the channel default.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscriptionID path string true "Subscription ID"
+// @Param request body setTemplateRequest true "Template source"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/subscriptions/{subscriptionID}/template [put]
+func setTemplateHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+			return
+		}
+		var req setTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sub, err := subscriptions.SetTemplate(c.Request.Context(), subscriptionID, req.Template)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+type setDeliveryModeRequest struct {
+	Mode          string `json:"mode" binding:"required"` // instant, hourly, daily or weekly
+	DigestTime    string `json:"digest_time"`             // "HH:MM" in the user's timezone; defaults to 09:00
+	DigestWeekday int    `json:"digest_weekday"`          // 0 (Sunday) to 6, for weekly digests
+}
+
+// setDeliveryModeHandler godoc
+// @Summary Set how often a subscription notifies
+// @Description "instant" sends each notification as it happens. "hourly", "daily" and "weekly" collect them into one digest per user and channel, sent at the top of the hour, daily at digest_time, or weekly on digest_weekday at digest_time, in the user's timezone. Webhook channels only support instant delivery.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscriptionID path string true "Subscription ID"
+// @Param request body setDeliveryModeRequest true "Delivery mode and digest schedule"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/subscriptions/{subscriptionID}/delivery [put]
+func setDeliveryModeHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+			return
+		}
+		var req setDeliveryModeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sub, err := subscriptions.SetDeliveryMode(c.Request.Context(), subscriptionID, req.Mode, req.DigestTime, req.DigestWeekday)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+type setChangeNotificationsRequest struct {
+	NotifyOnUpdate *bool `json:"notify_on_update"` // Omitted keeps the current setting
+	NotifyOnDelete *bool `json:"notify_on_delete"` // Omitted keeps the current setting
+}
+
+// setChangeNotificationsHandler godoc
+// @Summary Opt in or out of notifications about edited and retracted releases
+// @Description notify_on_update notifies when the release notes of a known release are edited; notify_on_delete when a release is retracted or deleted upstream. Omitted fields keep their value.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscriptionID path string true "Subscription ID"
+// @Param request body setChangeNotificationsRequest true "Change notification opt-ins"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/subscriptions/{subscriptionID}/notifications [put]
+func setChangeNotificationsHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+			return
+		}
+		var req setChangeNotificationsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sub, err := subscriptions.GetSubscription(c.Request.Context(), subscriptionID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		onUpdate, onDelete := sub.NotifyOnUpdate, sub.NotifyOnDelete
+		if req.NotifyOnUpdate != nil {
+			onUpdate = *req.NotifyOnUpdate
+		}
+		if req.NotifyOnDelete != nil {
+			onDelete = *req.NotifyOnDelete
+		}
+
+		sub, err = subscriptions.SetChangeNotifications(c.Request.Context(), sub.UserID, sub.RepoID, sub.Channel, onUpdate, onDelete)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+type setIncludeAssetsRequest struct {
+	IncludeAssets *bool `json:"include_assets" binding:"required"`
+}
+
+// setIncludeAssetsHandler godoc
+// @Summary Set whether notifications list release assets
+// @Description Asset names, sizes and, when the release publishes a checksums file, SHA-256 digests are listed below the release notes.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscriptionID path string true "Subscription ID"
+// @Param request body setIncludeAssetsRequest true "Whether to list assets"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/subscriptions/{subscriptionID}/assets [put]
+func setIncludeAssetsHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+			return
+		}
+		var req setIncludeAssetsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		sub, err := subscriptions.GetSubscription(c.Request.Context(), subscriptionID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		sub, err = subscriptions.SetIncludeAssets(c.Request.Context(), sub.UserID, sub.RepoID, sub.Channel, *req.IncludeAssets)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+type setTimezoneRequest struct {
+	Timezone string `json:"timezone"` // IANA name, e.g. "Europe/Berlin"; empty is UTC
+}
+
+// setTimezoneHandler godoc
+// @Summary Set the timezone of a user's digests
+// @Description Daily and weekly digests are scheduled in this timezone. Digests already scheduled keep their time.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body setTimezoneRequest true "IANA timezone name"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{userID}/timezone [put]
+func setTimezoneHandler(users usecase.UserUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.Param("userID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		var req setTimezoneRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		user, err := users.SetTimezone(c.Request.Context(), userID, req.Timezone)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+type setQuietHoursRequest struct {
+	Channel       string `json:"channel"`                  // Empty sets the user's default
+	Start         string `json:"start" binding:"required"` // "HH:MM" in the user's timezone
+	End           string `json:"end" binding:"required"`
+	Bundle        bool   `json:"bundle"`
+	AllowSecurity bool   `json:"allow_security"`
+}
+
+// setQuietHoursHandler godoc
+// @Summary Set quiet hours for a user or one of their channels
+// @Description Deliveries due between start and end, in the user's timezone, are deferred until the window ends; with bundle they are then sent as one digest. allow_security lets security releases through. A channel's own quiet hours win over the user's default (empty channel).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body setQuietHoursRequest true "Quiet hours window"
+// @Success 200 {object} domain.QuietHours
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{userID}/quiet-hours [put]
+func setQuietHoursHandler(users usecase.UserUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.Param("userID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		var req setQuietHoursRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		quiet, err := users.SetQuietHours(c.Request.Context(), &domain.QuietHours{
+			UserID:        userID,
+			Channel:       req.Channel,
+			Start:         req.Start,
+			End:           req.End,
+			Bundle:        req.Bundle,
+			AllowSecurity: req.AllowSecurity,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, quiet)
+	}
+}
+
+// listQuietHoursHandler godoc
+// @Summary List the quiet hours of a user
+// @Tags users
+// @Produce json
+// @Param userID path string true "User ID"
+// @Success 200 {array} domain.QuietHours
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{userID}/quiet-hours [get]
+func listQuietHoursHandler(users usecase.UserUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.Param("userID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+
+		quiet, err := users.ListQuietHours(c.Request.Context(), userID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, quiet)
+	}
+}
+
+// clearQuietHoursHandler godoc
+// @Summary Remove quiet hours of a user or one of their channels
+// @Tags users
+// @Param userID path string true "User ID"
+// @Param channel query string false "Channel whose quiet hours to remove; omit for the user's default"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/users/{userID}/quiet-hours [delete]
+func clearQuietHoursHandler(users usecase.UserUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.Param("userID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+
+		if err := users.ClearQuietHours(c.Request.Context(), userID, c.Query("channel")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listDeadDeliveriesHandler godoc
+// @Summary List dead-lettered deliveries
+// @Description Deliveries that ran out of send attempts, most recently failed first.
+// @Tags deliveries
+// @Produce json
+// @Param user_id query string false "Only deliveries of this user"
+// @Param limit query int false "Maximum number of deliveries, at most 500"
+// @Success 200 {array} domain.Delivery
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/deliveries/dead [get]
+func listDeadDeliveriesHandler(deliveries usecase.DeliveryUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var userID *uuid.UUID
+		if raw := c.Query("user_id"); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+				return
+			}
+			userID = &id
+		}
+		limit := 0
+		if raw := c.Query("limit"); raw != "" {
+			var err error
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+		}
+
+		dead, err := deliveries.ListDeadDeliveries(c.Request.Context(), userID, limit)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dead)
+	}
+}
+
+// retryDeliveryHandler godoc
+// @Summary Retry a dead-lettered delivery
+// @Description Requeues a dead or permanently failed delivery with a fresh set of attempts.
+// @Tags deliveries
+// @Produce json
+// @Param deliveryID path string true "Delivery ID"
+// @Success 200 {object} domain.Delivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/deliveries/{deliveryID}/retry [post]
+func retryDeliveryHandler(deliveries usecase.DeliveryUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deliveryID, err := uuid.Parse(c.Param("deliveryID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery ID"})
+			return
+		}
+
+		delivery, err := deliveries.RetryDelivery(c.Request.Context(), deliveryID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, delivery)
+	}
+}
+
+type previewTemplateRequest struct {
+	ReleaseID uuid.UUID `json:"release_id" binding:"required"`
+	Template  *string   `json:"template"` // Omit to preview the stored template
+}
+
+// previewTemplateHandler godoc
+// @Summary Preview a subscription's notification for a stored release
+// @Description Renders a new-release notification as the subscription would receive it, using the given template or, if omitted, the stored one.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscriptionID path string true "Subscription ID"
+// @Param request body previewTemplateRequest true "Release to render and optional template"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/subscriptions/{subscriptionID}/template/preview [post]
+func previewTemplateHandler(subscriptions usecase.SubscriptionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID, err := uuid.Parse(c.Param("subscriptionID"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription ID"})
+			return
+		}
+		var req previewTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		text, err := subscriptions.PreviewTemplate(c.Request.Context(), subscriptionID, req.ReleaseID, req.Template)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"text": text})
+	}
+}
+
+// defaultTemplateHandler godoc
+// @Summary Get the default message template of a channel type
+// @Tags subscriptions
+// @Produce json
+// @Param channelType path string true "Channel type, e.g. telegram or slack"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/templates/{channelType}/default [get]
+func defaultTemplateHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		channelType := domain.ChannelType(strings.ToLower(c.Param("channelType")))
+		c.JSON(http.StatusOK, gin.H{"channel_type": channelType, "template": notify.DefaultTemplate(channelType)})
+	}
+}
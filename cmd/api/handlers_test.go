@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/email"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/internal/usecase"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// fakeSubscriptions keeps subscriptions in memory; only the methods used by the tests are implemented.
+type fakeSubscriptions struct {
+	usecase.SubscriptionUseCase
+	subs map[uuid.UUID]*domain.Subscription
+}
+
+func (f *fakeSubscriptions) UnsubscribeByID(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	sub, ok := f.subs[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s: %w", subscriptionID, persistence.ErrNotFound)
+	}
+	delete(f.subs, subscriptionID)
+	return sub, nil
+}
+
+func (f *fakeSubscriptions) Subscribe(ctx context.Context, userID, repoID uuid.UUID, channel string) (*domain.Subscription, error) {
+	sub := &domain.Subscription{ID: uuid.New(), UserID: userID, RepoID: repoID, Channel: channel}
+	if strings.HasPrefix(channel, "webhook:") {
+		sub.Secret = "webhook-secret"
+	}
+	f.subs[sub.ID] = sub
+	return sub, nil
+}
+
+func (f *fakeSubscriptions) SetTemplate(ctx context.Context, subscriptionID uuid.UUID, template string) (*domain.Subscription, error) {
+	sub, ok := f.subs[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s: %w", subscriptionID, persistence.ErrNotFound)
+	}
+	sub.Template = template
+	return sub, nil
+}
+
+func (f *fakeSubscriptions) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	sub, ok := f.subs[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s: %w", subscriptionID, persistence.ErrNotFound)
+	}
+	return sub, nil
+}
+
+// find returns the subscription of a user to a repo on a channel, as the use case looks it up.
+func (f *fakeSubscriptions) find(userID, repoID uuid.UUID, channel string) (*domain.Subscription, error) {
+	for _, sub := range f.subs {
+		if sub.UserID == userID && sub.RepoID == repoID && sub.Channel == channel {
+			return sub, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription of user %s to repo %s on %s: %w", userID, repoID, channel, persistence.ErrNotFound)
+}
+
+func (f *fakeSubscriptions) SetChangeNotifications(ctx context.Context, userID, repoID uuid.UUID, channel string, onUpdate, onDelete bool) (*domain.Subscription, error) {
+	sub, err := f.find(userID, repoID, channel)
+	if err != nil {
+		return nil, err
+	}
+	sub.NotifyOnUpdate, sub.NotifyOnDelete = onUpdate, onDelete
+	return sub, nil
+}
+
+func (f *fakeSubscriptions) SetIncludeAssets(ctx context.Context, userID, repoID uuid.UUID, channel string, includeAssets bool) (*domain.Subscription, error) {
+	sub, err := f.find(userID, repoID, channel)
+	if err != nil {
+		return nil, err
+	}
+	sub.IncludeAssets = includeAssets
+	return sub, nil
+}
+
+// put sends a JSON PUT request to router.
+func put(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetChangeNotifications(t *testing.T) {
+	subs, mine, other := twoSubscriptions()
+	router := gin.New()
+	router.PUT("/subscriptions/:subscriptionID/notifications", setChangeNotificationsHandler(subs))
+
+	w := put(router, "/subscriptions/"+mine.String()+"/notifications", `{"notify_on_update":true,"notify_on_delete":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if sub := subs.subs[mine]; !sub.NotifyOnUpdate || !sub.NotifyOnDelete {
+		t.Errorf("subscription = %+v, want both opt-ins set", sub)
+	}
+	if sub := subs.subs[other]; sub.NotifyOnUpdate || sub.NotifyOnDelete {
+		t.Error("another subscription was changed")
+	}
+
+	// An omitted field keeps its value
+	w = put(router, "/subscriptions/"+mine.String()+"/notifications", `{"notify_on_delete":false}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("partial update status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if sub := subs.subs[mine]; !sub.NotifyOnUpdate || sub.NotifyOnDelete {
+		t.Errorf("after partial update subscription = %+v, want only notify_on_update", sub)
+	}
+
+	for path, want := range map[string]int{
+		"/subscriptions/" + uuid.NewString() + "/notifications": http.StatusNotFound,
+		"/subscriptions/not-a-uuid/notifications":               http.StatusBadRequest,
+	} {
+		if w := put(router, path, `{"notify_on_update":true}`); w.Code != want {
+			t.Errorf("PUT %s status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+func TestSetIncludeAssets(t *testing.T) {
+	subs, mine, other := twoSubscriptions()
+	router := gin.New()
+	router.PUT("/subscriptions/:subscriptionID/assets", setIncludeAssetsHandler(subs))
+
+	w := put(router, "/subscriptions/"+mine.String()+"/assets", `{"include_assets":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if !subs.subs[mine].IncludeAssets || subs.subs[other].IncludeAssets {
+		t.Errorf("include_assets = %t, other subscription %t; want only this one set", subs.subs[mine].IncludeAssets, subs.subs[other].IncludeAssets)
+	}
+
+	if w := put(router, "/subscriptions/"+mine.String()+"/assets", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing include_assets: status = %d, want 400", w.Code)
+	}
+	if !subs.subs[mine].IncludeAssets {
+		t.Error("an invalid request changed the setting")
+	}
+}
+
+func TestWebhookSecretIsOnlyReturnedOnCreation(t *testing.T) {
+	subs := &fakeSubscriptions{subs: map[uuid.UUID]*domain.Subscription{}}
+	router := gin.New()
+	router.POST("/repos/:repoID/subscribe", subscribeHandler(subs))
+	router.PUT("/subscriptions/:subscriptionID/template", setTemplateHandler(subs))
+
+	body := fmt.Sprintf(`{"userID":%q,"channel":"webhook:https://hooks.example.com/rr"}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/repos/"+uuid.NewString()+"/subscribe", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("subscribe status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var created struct {
+		ID     uuid.UUID `json:"id"`
+		Secret string    `json:"secret"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode subscribe response: %v", err)
+	}
+	if created.Secret != "webhook-secret" {
+		t.Errorf("subscribe response secret = %q, want the signing secret", created.Secret)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/subscriptions/"+created.ID.String()+"/template", strings.NewReader(`{"template":"{{.Repo}}"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("set template status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("subscription response leaks the secret: %s", w.Body)
+	}
+}
+
+var unsubscribeSecret = []byte("unsubscribe-secret")
+
+func newUnsubscribeRouter(subs *fakeSubscriptions) *gin.Engine {
+	router := gin.New()
+	router.GET(email.UnsubscribePath, unsubscribeHandler(subs, unsubscribeSecret))
+	router.POST(email.UnsubscribePath, unsubscribeHandler(subs, unsubscribeSecret))
+	return router
+}
+
+func unsubscribe(router *gin.Engine, method, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, email.UnsubscribePath+"?token="+token, strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func twoSubscriptions() (*fakeSubscriptions, uuid.UUID, uuid.UUID) {
+	// The same user and channel, subscribed to two repos
+	mine, other, user := uuid.New(), uuid.New(), uuid.New()
+	return &fakeSubscriptions{subs: map[uuid.UUID]*domain.Subscription{
+		mine:  {ID: mine, UserID: user, RepoID: uuid.New(), Channel: "email:me@example.com"},
+		other: {ID: other, UserID: user, RepoID: uuid.New(), Channel: "email:me@example.com"},
+	}}, mine, other
+}
+
+func TestUnsubscribePostRemovesOnlyTheSignedSubscription(t *testing.T) {
+	subs, mine, other := twoSubscriptions()
+	router := newUnsubscribeRouter(subs)
+
+	w := unsubscribe(router, http.MethodPost, email.UnsubscribeToken(unsubscribeSecret, mine))
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if _, ok := subs.subs[mine]; ok {
+		t.Error("signed subscription was not removed")
+	}
+	if _, ok := subs.subs[other]; !ok {
+		t.Error("another subscription was removed")
+	}
+
+	// The link keeps working, but there is nothing left to remove
+	w = unsubscribe(router, http.MethodPost, email.UnsubscribeToken(unsubscribeSecret, mine))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second POST status = %d, want 404", w.Code)
+	}
+}
+
+func TestUnsubscribeGetRemovesNothing(t *testing.T) {
+	subs, mine, _ := twoSubscriptions()
+	router := newUnsubscribeRouter(subs)
+
+	w := unsubscribe(router, http.MethodGet, email.UnsubscribeToken(unsubscribeSecret, mine))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `<form method="post"`) {
+		t.Errorf("GET did not render the confirmation form:\n%s", w.Body)
+	}
+	if len(subs.subs) != 2 {
+		t.Error("GET removed a subscription; link prefetching would unsubscribe people")
+	}
+}
+
+func TestUnsubscribeRejectsTamperedToken(t *testing.T) {
+	subs, mine, other := twoSubscriptions()
+	router := newUnsubscribeRouter(subs)
+
+	// Swap in another subscription's ID while keeping the signature of the original
+	token := email.UnsubscribeToken(unsubscribeSecret, mine)
+	otherToken := email.UnsubscribeToken(unsubscribeSecret, other)
+	_, signature, _ := strings.Cut(token, ".")
+	otherPayload, _, _ := strings.Cut(otherToken, ".")
+
+	for name, tampered := range map[string]string{
+		"swapped payload": otherPayload + "." + signature,
+		"foreign secret":  email.UnsubscribeToken([]byte("someone-else"), other),
+		"truncated":       token[:len(token)-2],
+		"missing":         "",
+	} {
+		w := unsubscribe(router, http.MethodPost, tampered)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+	if len(subs.subs) != 2 {
+		t.Errorf("a tampered token removed a subscription; %d left", len(subs.subs))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,9 +13,19 @@ import (
 	"github.com/google/uuid"
 
 	// "github.com/mackb/releaseradar/internal/adapter/cache" // Удален неиспользуемый импорт
+	"github.com/mackb/releaseradar/internal/adapter/discord"
+	"github.com/mackb/releaseradar/internal/adapter/email"
 	"github.com/mackb/releaseradar/internal/adapter/github"
+	"github.com/mackb/releaseradar/internal/adapter/gotify"
+	"github.com/mackb/releaseradar/internal/adapter/matrix"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/ntfy"
 	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/adapter/slack"
+	"github.com/mackb/releaseradar/internal/adapter/teams"
 	"github.com/mackb/releaseradar/internal/adapter/telegram"
+	"github.com/mackb/releaseradar/internal/adapter/webhook"
+	"github.com/mackb/releaseradar/internal/domain"
 	"github.com/mackb/releaseradar/internal/usecase"
 	"github.com/mackb/releaseradar/pkg/idempotency"
 	"github.com/mackb/releaseradar/pkg/logger"
@@ -31,7 +42,8 @@ import (
 )
 
 func init() {
-	vipHook := viper.New()
+	// Configure the global instance: main reads every setting through viper.Get*
+	vipHook := viper.GetViper()
 	vipHook.AutomaticEnv()
 	vipHook.SetEnvPrefix("RR")
 	vipHook.SetDefault("LOG_LEVEL", "info")
@@ -40,6 +52,18 @@ func init() {
 	vipHook.SetDefault("REDIS_ADDR", "localhost:6379")
 	vipHook.SetDefault("GITHUB_TOKEN", "")
 	vipHook.SetDefault("TELEGRAM_BOT_TOKEN", "")
+	vipHook.SetDefault("EMAIL_SMTP_HOST", "")
+	vipHook.SetDefault("EMAIL_SMTP_PORT", 587)
+	vipHook.SetDefault("EMAIL_SMTP_USERNAME", "")
+	vipHook.SetDefault("EMAIL_SMTP_PASSWORD", "")
+	vipHook.SetDefault("EMAIL_FROM", "ReleaseRadar <noreply@localhost>")
+	vipHook.SetDefault("PUBLIC_BASE_URL", "http://localhost:8080")
+	vipHook.SetDefault("UNSUBSCRIBE_SECRET", "")
+	vipHook.SetDefault("MATRIX_HOMESERVER_URL", "")
+	vipHook.SetDefault("MATRIX_ACCESS_TOKEN", "")
+	vipHook.SetDefault("TELEGRAM_UPDATES", "") // "polling", "webhook", or empty to not answer bot commands
+	vipHook.SetDefault("TELEGRAM_WEBHOOK_SECRET", "")
+	vipHook.SetDefault("TELEGRAM_CALLBACK_SECRET", "")
 
 	// Bind environment variables manually to avoid issues with hyphens if used in config names
 	_ = vipHook.BindEnv("LOG_LEVEL")
@@ -48,6 +72,18 @@ func init() {
 	_ = vipHook.BindEnv("REDIS_ADDR")
 	_ = vipHook.BindEnv("GITHUB_TOKEN")
 	_ = vipHook.BindEnv("TELEGRAM_BOT_TOKEN")
+	_ = vipHook.BindEnv("EMAIL_SMTP_HOST")
+	_ = vipHook.BindEnv("EMAIL_SMTP_PORT")
+	_ = vipHook.BindEnv("EMAIL_SMTP_USERNAME")
+	_ = vipHook.BindEnv("EMAIL_SMTP_PASSWORD")
+	_ = vipHook.BindEnv("EMAIL_FROM")
+	_ = vipHook.BindEnv("PUBLIC_BASE_URL")
+	_ = vipHook.BindEnv("UNSUBSCRIBE_SECRET")
+	_ = vipHook.BindEnv("MATRIX_HOMESERVER_URL")
+	_ = vipHook.BindEnv("MATRIX_ACCESS_TOKEN")
+	_ = vipHook.BindEnv("TELEGRAM_CALLBACK_SECRET")
+	_ = vipHook.BindEnv("TELEGRAM_UPDATES")
+	_ = vipHook.BindEnv("TELEGRAM_WEBHOOK_SECRET")
 
 	vipHook.ReadInConfig() // Read config file if exists (e.g., .env)
 }
@@ -106,12 +142,45 @@ func main() {
 		log.Fatal("failed to create telegram client", zap.Error(err))
 	}
 
+	// Route deliveries by channel type ("telegram:<chat>", "slack:<webhook>", ...)
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	// Webhook, ntfy and Gotify URLs come from subscribers and may only reach public addresses
+	publicClient := notify.NewPublicClient(10 * time.Second)
+	channels := notify.NewRegistry()
+	channels.Register(domain.ChannelTelegram, telegram.NewNotifier(telegramClient, []byte(viper.GetString("TELEGRAM_CALLBACK_SECRET")), dbStore))
+	channels.Register(domain.ChannelSlack, slack.NewNotifier(httpClient))
+	channels.Register(domain.ChannelDiscord, discord.NewNotifier(httpClient))
+	channels.Register(domain.ChannelWebhook, webhook.NewNotifier(publicClient))
+	channels.Register(domain.ChannelTeams, teams.NewNotifier(httpClient))
+	channels.Register(domain.ChannelNtfy, ntfy.NewNotifier(publicClient))
+	channels.Register(domain.ChannelGotify, gotify.NewNotifier(publicClient))
+	if homeserver := viper.GetString("MATRIX_HOMESERVER_URL"); homeserver != "" {
+		channels.Register(domain.ChannelMatrix, matrix.NewNotifier(httpClient, homeserver, viper.GetString("MATRIX_ACCESS_TOKEN")))
+	}
+	if smtpHost := viper.GetString("EMAIL_SMTP_HOST"); smtpHost != "" {
+		smtpPort := viper.GetInt("EMAIL_SMTP_PORT")
+		if smtpPort == 0 {
+			smtpPort = 587
+		}
+		channels.Register(domain.ChannelEmail, email.NewNotifier(email.Config{
+			Host:              smtpHost,
+			Port:              smtpPort,
+			Username:          viper.GetString("EMAIL_SMTP_USERNAME"),
+			Password:          viper.GetString("EMAIL_SMTP_PASSWORD"),
+			From:              viper.GetString("EMAIL_FROM"),
+			PublicBaseURL:     viper.GetString("PUBLIC_BASE_URL"),
+			UnsubscribeSecret: []byte(viper.GetString("UNSUBSCRIBE_SECRET")),
+		}))
+	}
+
 	// Initialize usecases
-	_ = usecase.NewUserUseCase(dbStore, dbStore)                                                           // Удалена неиспользуемая переменная userUseCase
-	_ = usecase.NewRepoUseCase(dbStore, dbStore, githubClient, dbStore)                                    // Удалена неиспользуемая переменная repoUseCase
-	_ = usecase.NewSubscriptionUseCase(dbStore, dbStore, dbStore, dbStore)                                 // Удалена неиспользуемая переменная subscriptionUseCase
-	_ = usecase.NewPollerUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, githubClient, dbStore)       // Удалена неиспользуемая переменная pollerUseCase
-	_ = usecase.NewNotifierUseCase(dbStore, dbStore, dbStore, telegramClient, idempotencyManager, dbStore) // Удалена неиспользуемая переменная notifierUseCase
+	_ = usecase.NewPollerUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, githubClient, dbStore, nil, usecase.DefaultMaxNotFound)                                                                                       // Удалена неиспользуемая переменная pollerUseCase
+	_ = usecase.NewNotifierUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, dbStore, channels, idempotencyManager, dbStore, usecase.DefaultRetryPolicy, usecase.DefaultClaimPolicy, usecase.DefaultNotifierConcurrency) // Удалена неиспользуемая переменная notifierUseCase
+	userUseCase := usecase.NewUserUseCase(dbStore, dbStore, dbStore)
+	repoUseCase := usecase.NewRepoUseCase(dbStore, dbStore, githubClient, dbStore)
+	subscriptionUseCase := usecase.NewSubscriptionUseCase(dbStore, dbStore, dbStore, dbStore, channels, dbStore)
+	releaseUseCase := usecase.NewReleaseUseCase(dbStore)
+	deliveryUseCase := usecase.NewDeliveryUseCase(dbStore)
 
 	// _ = &usecase.Usecases{ // Удалена неиспользуемая переменная appUsecases
 	// 	User:         userUseCase,
@@ -121,6 +190,37 @@ func main() {
 	// 	Notifier:     notifierUseCase,
 	// }
 
+	unsubscribeSecret := []byte(viper.GetString("UNSUBSCRIBE_SECRET"))
+	if len(unsubscribeSecret) == 0 {
+		log.Warn("UNSUBSCRIBE_SECRET is not set; unsubscribe links cannot be verified")
+	}
+
+	// Answer bot commands (/watch, /list, ...) and notification buttons by long polling or through a webhook
+	bot := newTelegramBot(telegramClient, userUseCase, repoUseCase, subscriptionUseCase, releaseUseCase, []byte(viper.GetString("TELEGRAM_CALLBACK_SECRET")))
+	botCtx, stopBot := context.WithCancel(context.Background())
+	defer stopBot()
+	telegramUpdates := viper.GetString("TELEGRAM_UPDATES")
+	webhookSecret := viper.GetString("TELEGRAM_WEBHOOK_SECRET")
+	switch telegramUpdates {
+	case "":
+	case "polling":
+		go func() {
+			if err := telegramClient.PollUpdates(botCtx, bot.handleUpdate); err != nil {
+				log.Error("telegram update polling stopped", zap.Error(err))
+			}
+		}()
+	case "webhook":
+		if webhookSecret == "" {
+			log.Fatal("TELEGRAM_WEBHOOK_SECRET is required when TELEGRAM_UPDATES is webhook")
+		}
+		webhookURL := strings.TrimSuffix(viper.GetString("PUBLIC_BASE_URL"), "/") + telegramWebhookPath
+		if err := telegramClient.SetWebhook(botCtx, webhookURL, webhookSecret); err != nil {
+			log.Fatal("failed to set telegram webhook", zap.Error(err))
+		}
+	default:
+		log.Fatal("TELEGRAM_UPDATES must be polling, webhook or empty", zap.String("value", telegramUpdates))
+	}
+
 	// Set up Gin router
 	r := gin.New()
 	r.Use(gin.Recovery())
@@ -144,7 +244,25 @@ func main() {
 		v1.POST("/signup", func(c *gin.Context) { /* signup stub */ })
 		v1.POST("/repos", func(c *gin.Context) { /* add repo stub */ })
 		v1.GET("/repos", func(c *gin.Context) { /* list repos stub */ })
-		v1.POST("/repos/:repoID/subscribe", func(c *gin.Context) { /* subscribe stub */ })
+		v1.POST("/repos/:repoID/subscribe", subscribeHandler(subscriptionUseCase))
+		v1.GET("/releases/:releaseID/assets", listReleaseAssetsHandler(releaseUseCase))
+		v1.GET("/unsubscribe", unsubscribeHandler(subscriptionUseCase, unsubscribeSecret))
+		v1.POST("/unsubscribe", unsubscribeHandler(subscriptionUseCase, unsubscribeSecret))
+		v1.PUT("/subscriptions/:subscriptionID/template", setTemplateHandler(subscriptionUseCase))
+		v1.POST("/subscriptions/:subscriptionID/template/preview", previewTemplateHandler(subscriptionUseCase))
+		v1.GET("/templates/:channelType/default", defaultTemplateHandler())
+		v1.PUT("/subscriptions/:subscriptionID/delivery", setDeliveryModeHandler(subscriptionUseCase))
+		v1.PUT("/subscriptions/:subscriptionID/notifications", setChangeNotificationsHandler(subscriptionUseCase))
+		v1.PUT("/subscriptions/:subscriptionID/assets", setIncludeAssetsHandler(subscriptionUseCase))
+		v1.PUT("/users/:userID/timezone", setTimezoneHandler(userUseCase))
+		v1.GET("/users/:userID/quiet-hours", listQuietHoursHandler(userUseCase))
+		v1.PUT("/users/:userID/quiet-hours", setQuietHoursHandler(userUseCase))
+		v1.DELETE("/users/:userID/quiet-hours", clearQuietHoursHandler(userUseCase))
+		v1.GET("/deliveries/dead", listDeadDeliveriesHandler(deliveryUseCase))
+		v1.POST("/deliveries/:deliveryID/retry", retryDeliveryHandler(deliveryUseCase))
+	}
+	if telegramUpdates == "webhook" {
+		r.POST(telegramWebhookPath, telegramWebhookHandler(telegramClient, bot, webhookSecret))
 	}
 
 	httpPort := viper.GetString("HTTP_PORT")
@@ -165,6 +283,7 @@ func main() {
 
 	<-p
 	log.Info("Shutting down server...")
+	stopBot()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -5,25 +5,47 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	// "fmt"
 
+	"github.com/mackb/releaseradar/internal/adapter/discord"
+	"github.com/mackb/releaseradar/internal/adapter/email"
 	"github.com/mackb/releaseradar/internal/adapter/github"
+	"github.com/mackb/releaseradar/internal/adapter/gotify"
+	"github.com/mackb/releaseradar/internal/adapter/matrix"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/ntfy"
 	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/adapter/queue"
+	"github.com/mackb/releaseradar/internal/adapter/slack"
+	"github.com/mackb/releaseradar/internal/adapter/teams"
 	"github.com/mackb/releaseradar/internal/adapter/telegram"
+	"github.com/mackb/releaseradar/internal/adapter/webhook"
+	"github.com/mackb/releaseradar/internal/domain"
 	"github.com/mackb/releaseradar/internal/usecase"
 	"github.com/mackb/releaseradar/pkg/idempotency"
 	"github.com/mackb/releaseradar/pkg/logger"
 	"github.com/mackb/releaseradar/pkg/otel"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// Intervals of the worker loops, used when the setting is missing or not positive
+const (
+	defaultPollerInterval    = 5 * time.Minute
+	defaultNotifierInterval  = time.Minute
+	defaultDigestInterval    = time.Minute
+	defaultReconcileInterval = 10 * time.Minute
+)
+
 func init() {
-	vipHook := viper.New()
+	// Configure the global instance: main reads every setting through viper.Get*
+	vipHook := viper.GetViper()
 	vipHook.AutomaticEnv()
 	vipHook.SetEnvPrefix("RR_WORKER")
 	vipHook.SetDefault("LOG_LEVEL", "info")
@@ -31,16 +53,73 @@ func init() {
 	vipHook.SetDefault("REDIS_ADDR", "localhost:6379")
 	vipHook.SetDefault("GITHUB_TOKEN", "")
 	vipHook.SetDefault("TELEGRAM_BOT_TOKEN", "")
-	vipHook.SetDefault("POLLER_INTERVAL_MINUTES", 5)
-	vipHook.SetDefault("NOTIFIER_INTERVAL_SECONDS", 10)
+	vipHook.SetDefault("EMAIL_SMTP_HOST", "")
+	vipHook.SetDefault("EMAIL_SMTP_PORT", 587)
+	vipHook.SetDefault("EMAIL_SMTP_USERNAME", "")
+	vipHook.SetDefault("EMAIL_SMTP_PASSWORD", "")
+	vipHook.SetDefault("EMAIL_FROM", "ReleaseRadar <noreply@localhost>")
+	vipHook.SetDefault("PUBLIC_BASE_URL", "http://localhost:8080")
+	vipHook.SetDefault("UNSUBSCRIBE_SECRET", "")
+	vipHook.SetDefault("MATRIX_HOMESERVER_URL", "")
+	vipHook.SetDefault("MATRIX_ACCESS_TOKEN", "")
+	vipHook.SetDefault("TELEGRAM_CALLBACK_SECRET", "") // Signs notification buttons; shared by the API and worker
+	vipHook.SetDefault("POLLER_INTERVAL_MINUTES", int(defaultPollerInterval/time.Minute))
+	vipHook.SetDefault("NOTIFIER_INTERVAL_SECONDS", int(defaultNotifierInterval/time.Second)) // Sweep for what the delivery stream missed, and for due retries
+	vipHook.SetDefault("DIGEST_INTERVAL_SECONDS", int(defaultDigestInterval/time.Second))
+	vipHook.SetDefault("RECONCILE_INTERVAL_MINUTES", int(defaultReconcileInterval/time.Minute))
+	vipHook.SetDefault("REPO_MAX_NOT_FOUND", usecase.DefaultMaxNotFound)
+	vipHook.SetDefault("DELIVERY_MAX_ATTEMPTS", usecase.DefaultRetryPolicy.MaxAttempts)
+	vipHook.SetDefault("DELIVERY_RETRY_BASE_SECONDS", int(usecase.DefaultRetryPolicy.BaseDelay/time.Second))
+	vipHook.SetDefault("DELIVERY_RETRY_MAX_SECONDS", int(usecase.DefaultRetryPolicy.MaxDelay/time.Second))
+	vipHook.SetDefault("METRICS_ADDR", ":9091")
+	vipHook.SetDefault("WORKER_ID", "") // Claim owner of deliveries and stream consumer name; defaults to <hostname>-<pid>
+	vipHook.SetDefault("DELIVERY_STREAM_ENABLED", true)
+	vipHook.SetDefault("DELIVERY_STREAM", "releaseradar:deliveries") // Redis Stream announcing new deliveries
+	vipHook.SetDefault("DELIVERY_STREAM_GROUP", "notifier")
+	vipHook.SetDefault("CLAIM_BATCH_SIZE", usecase.DefaultClaimPolicy.BatchSize)
+	vipHook.SetDefault("CLAIM_LEASE_SECONDS", int(usecase.DefaultClaimPolicy.Lease/time.Second))
+	vipHook.SetDefault("NOTIFIER_CONCURRENCY", usecase.DefaultNotifierConcurrency)
+	// Send rates per channel type in messages per second, e.g. RATE_LIMIT_TELEGRAM and
+	// RATE_LIMIT_TELEGRAM_PER_DESTINATION; 0 is unlimited
+	for channelType, limits := range notify.DefaultRateLimits {
+		key := "RATE_LIMIT_" + strings.ToUpper(string(channelType))
+		vipHook.SetDefault(key, limits.Global)
+		vipHook.SetDefault(key+"_PER_DESTINATION", limits.PerDestination)
+		_ = vipHook.BindEnv(key)
+		_ = vipHook.BindEnv(key + "_PER_DESTINATION")
+	}
 
 	_ = vipHook.BindEnv("LOG_LEVEL")
 	_ = vipHook.BindEnv("POSTGRES_DSN")
 	_ = vipHook.BindEnv("REDIS_ADDR")
 	_ = vipHook.BindEnv("GITHUB_TOKEN")
 	_ = vipHook.BindEnv("TELEGRAM_BOT_TOKEN")
+	_ = vipHook.BindEnv("EMAIL_SMTP_HOST")
+	_ = vipHook.BindEnv("EMAIL_SMTP_PORT")
+	_ = vipHook.BindEnv("EMAIL_SMTP_USERNAME")
+	_ = vipHook.BindEnv("EMAIL_SMTP_PASSWORD")
+	_ = vipHook.BindEnv("EMAIL_FROM")
+	_ = vipHook.BindEnv("PUBLIC_BASE_URL")
+	_ = vipHook.BindEnv("UNSUBSCRIBE_SECRET")
+	_ = vipHook.BindEnv("MATRIX_HOMESERVER_URL")
+	_ = vipHook.BindEnv("MATRIX_ACCESS_TOKEN")
+	_ = vipHook.BindEnv("TELEGRAM_CALLBACK_SECRET")
 	_ = vipHook.BindEnv("POLLER_INTERVAL_MINUTES")
 	_ = vipHook.BindEnv("NOTIFIER_INTERVAL_SECONDS")
+	_ = vipHook.BindEnv("DIGEST_INTERVAL_SECONDS")
+	_ = vipHook.BindEnv("RECONCILE_INTERVAL_MINUTES")
+	_ = vipHook.BindEnv("REPO_MAX_NOT_FOUND")
+	_ = vipHook.BindEnv("DELIVERY_MAX_ATTEMPTS")
+	_ = vipHook.BindEnv("DELIVERY_RETRY_BASE_SECONDS")
+	_ = vipHook.BindEnv("DELIVERY_RETRY_MAX_SECONDS")
+	_ = vipHook.BindEnv("METRICS_ADDR")
+	_ = vipHook.BindEnv("WORKER_ID")
+	_ = vipHook.BindEnv("DELIVERY_STREAM_ENABLED")
+	_ = vipHook.BindEnv("DELIVERY_STREAM")
+	_ = vipHook.BindEnv("DELIVERY_STREAM_GROUP")
+	_ = vipHook.BindEnv("CLAIM_BATCH_SIZE")
+	_ = vipHook.BindEnv("CLAIM_LEASE_SECONDS")
+	_ = vipHook.BindEnv("NOTIFIER_CONCURRENCY")
 
 	vipHook.ReadInConfig()
 }
@@ -79,14 +158,88 @@ func main() {
 		log.Fatal("failed to create telegram client", zap.Error(err))
 	}
 
-	pollerUseCase := usecase.NewPollerUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, githubClient, dbStore)         // Обновленный вызов
-	notifierUseCase := usecase.NewNotifierUseCase(dbStore, dbStore, dbStore, telegramClient, idempotencyManager, dbStore) // Обновленный вызов
+	// Route deliveries by channel type ("telegram:<chat>", "slack:<webhook>", ...)
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	// Webhook, ntfy and Gotify URLs come from subscribers and may only reach public addresses
+	publicClient := notify.NewPublicClient(10 * time.Second)
+	channels := notify.NewRegistry()
+	channels.Register(domain.ChannelTelegram, telegram.NewNotifier(telegramClient, []byte(viper.GetString("TELEGRAM_CALLBACK_SECRET")), dbStore))
+	channels.Register(domain.ChannelSlack, slack.NewNotifier(httpClient))
+	channels.Register(domain.ChannelDiscord, discord.NewNotifier(httpClient))
+	channels.Register(domain.ChannelWebhook, webhook.NewNotifier(publicClient))
+	channels.Register(domain.ChannelTeams, teams.NewNotifier(httpClient))
+	channels.Register(domain.ChannelNtfy, ntfy.NewNotifier(publicClient))
+	channels.Register(domain.ChannelGotify, gotify.NewNotifier(publicClient))
+	if homeserver := viper.GetString("MATRIX_HOMESERVER_URL"); homeserver != "" {
+		channels.Register(domain.ChannelMatrix, matrix.NewNotifier(httpClient, homeserver, viper.GetString("MATRIX_ACCESS_TOKEN")))
+	}
+	if smtpHost := viper.GetString("EMAIL_SMTP_HOST"); smtpHost != "" {
+		smtpPort := viper.GetInt("EMAIL_SMTP_PORT")
+		if smtpPort == 0 {
+			smtpPort = 587
+		}
+		channels.Register(domain.ChannelEmail, email.NewNotifier(email.Config{
+			Host:              smtpHost,
+			Port:              smtpPort,
+			Username:          viper.GetString("EMAIL_SMTP_USERNAME"),
+			Password:          viper.GetString("EMAIL_SMTP_PASSWORD"),
+			From:              viper.GetString("EMAIL_FROM"),
+			PublicBaseURL:     viper.GetString("PUBLIC_BASE_URL"),
+			UnsubscribeSecret: []byte(viper.GetString("UNSUBSCRIBE_SECRET")),
+		}))
+	}
+
+	for channelType := range notify.DefaultRateLimits {
+		key := "RATE_LIMIT_" + strings.ToUpper(string(channelType))
+		channels.SetRateLimits(channelType, notify.RateLimits{
+			Global:         viper.GetFloat64(key),
+			PerDestination: viper.GetFloat64(key + "_PER_DESTINATION"),
+		})
+	}
+
+	workerID := viper.GetString("WORKER_ID")
+	if workerID == "" {
+		workerID = usecase.DefaultWorkerID()
+	}
+
+	// Delivery stream: the poller announces new deliveries on it so that they are sent right
+	// away instead of on the next notifier sweep
+	var deliveryStream *queue.RedisStream
+	var deliveryPublisher queue.Publisher
+	if viper.GetBool("DELIVERY_STREAM_ENABLED") {
+		deliveryStream = queue.NewRedisStream(redisClient, viper.GetString("DELIVERY_STREAM"), viper.GetString("DELIVERY_STREAM_GROUP"), workerID)
+		if err := deliveryStream.EnsureGroup(context.Background()); err != nil {
+			log.Fatal("Failed to create delivery stream consumer group", zap.Error(err))
+		}
+		deliveryPublisher = deliveryStream
+	}
+
+	pollerUseCase := usecase.NewPollerUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, githubClient, dbStore, deliveryPublisher, viper.GetInt("REPO_MAX_NOT_FOUND")) // Обновленный вызов
+	retryPolicy := usecase.RetryPolicy{
+		MaxAttempts: viper.GetInt("DELIVERY_MAX_ATTEMPTS"),
+		BaseDelay:   time.Duration(viper.GetInt("DELIVERY_RETRY_BASE_SECONDS")) * time.Second,
+		MaxDelay:    time.Duration(viper.GetInt("DELIVERY_RETRY_MAX_SECONDS")) * time.Second,
+	}
+	claimPolicy := usecase.ClaimPolicy{
+		WorkerID:  workerID,
+		BatchSize: viper.GetInt("CLAIM_BATCH_SIZE"),
+		Lease:     time.Duration(viper.GetInt("CLAIM_LEASE_SECONDS")) * time.Second,
+	}
+	notifierUseCase := usecase.NewNotifierUseCase(dbStore, dbStore, dbStore, dbStore, dbStore, dbStore, channels, idempotencyManager, dbStore, retryPolicy, claimPolicy, viper.GetInt("NOTIFIER_CONCURRENCY"))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Prometheus metrics endpoint, e.g. releaseradar_deliveries_dead_total
+	metricsServer := &http.Server{Addr: viper.GetString("METRICS_ADDR"), Handler: promhttp.Handler()}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("metrics server failed", zap.Error(err))
+		}
+	}()
+
 	// Poller loop
-	pollerInterval := time.Duration(viper.GetInt("POLLER_INTERVAL_MINUTES")) * time.Minute
+	pollerInterval := loopInterval("POLLER_INTERVAL_MINUTES", time.Minute, defaultPollerInterval)
 	go func() {
 		ticker := time.NewTicker(pollerInterval)
 		defer ticker.Stop()
@@ -106,7 +259,7 @@ func main() {
 	}()
 
 	// Notifier loop
-	notifierInterval := time.Duration(viper.GetInt("NOTIFIER_INTERVAL_SECONDS")) * time.Second
+	notifierInterval := loopInterval("NOTIFIER_INTERVAL_SECONDS", time.Second, defaultNotifierInterval)
 	go func() {
 		ticker := time.NewTicker(notifierInterval)
 		defer ticker.Stop()
@@ -125,6 +278,60 @@ func main() {
 		}
 	}()
 
+	// Delivery stream consumer: sends deliveries as soon as the poller announces them
+	if deliveryStream != nil {
+		go func() {
+			if err := notifierUseCase.ConsumeDeliveries(ctx, deliveryStream); err != nil {
+				log.Error("Delivery stream consumer failed", zap.Error(err))
+			}
+		}()
+	}
+
+	// Digest loop: sends the hourly, daily and weekly digests that are due, and releases the
+	// deliveries held back by quiet hours that have ended
+	digestInterval := loopInterval("DIGEST_INTERVAL_SECONDS", time.Second, defaultDigestInterval)
+	go func() {
+		ticker := time.NewTicker(digestInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				log.Info("Running digest use case")
+				err := notifierUseCase.SendDigests(ctx)
+				if err != nil {
+					log.Error("Digest use case failed", zap.Error(err))
+				}
+				err = notifierUseCase.ReleaseDeferred(ctx)
+				if err != nil {
+					log.Error("Releasing deferred deliveries failed", zap.Error(err))
+				}
+			case <-ctx.Done():
+				log.Info("Digest sender stopped")
+				return
+			}
+		}
+	}()
+
+	// Reconciler loop: backfills deliveries of releases that subscribers were never notified about
+	reconcileInterval := loopInterval("RECONCILE_INTERVAL_MINUTES", time.Minute, defaultReconcileInterval)
+	go func() {
+		ticker := time.NewTicker(reconcileInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				log.Info("Running delivery reconciler")
+				err := pollerUseCase.ReconcileDeliveries(ctx)
+				if err != nil {
+					log.Error("Delivery reconciler failed", zap.Error(err))
+				}
+			case <-ctx.Done():
+				log.Info("Delivery reconciler stopped")
+				return
+			}
+		}
+	}()
+
 	// Wait for interrupt signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
@@ -132,7 +339,19 @@ func main() {
 
 	log.Info("Shutting down worker...")
 	cancel()
+	_ = metricsServer.Close()
 	// Give some time for goroutines to finish
 	time.Sleep(2 * time.Second)
 	log.Info("Worker exited")
 }
+
+// loopInterval reads the interval setting key, counted in unit. A value that is not positive
+// would make time.NewTicker panic, so it is replaced by fallback.
+func loopInterval(key string, unit, fallback time.Duration) time.Duration {
+	n := viper.GetInt(key)
+	if n <= 0 {
+		logger.L().Sugar().Warnf("%s is %d, using %s instead", key, n, fallback)
+		return fallback
+	}
+	return time.Duration(n) * unit
+}
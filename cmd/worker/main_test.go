@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+func TestDeliveryStreamEnabledByDefault(t *testing.T) {
+	if !viper.GetBool("DELIVERY_STREAM_ENABLED") {
+		t.Error("DELIVERY_STREAM_ENABLED = false, want true")
+	}
+	if got := viper.GetString("DELIVERY_STREAM"); got != "releaseradar:deliveries" {
+		t.Errorf("DELIVERY_STREAM = %q, want %q", got, "releaseradar:deliveries")
+	}
+	if got := viper.GetString("DELIVERY_STREAM_GROUP"); got != "notifier" {
+		t.Errorf("DELIVERY_STREAM_GROUP = %q, want %q", got, "notifier")
+	}
+}
+
+func TestDeliveryStreamCanBeDisabled(t *testing.T) {
+	t.Setenv("RR_WORKER_DELIVERY_STREAM_ENABLED", "false")
+
+	if viper.GetBool("DELIVERY_STREAM_ENABLED") {
+		t.Error("DELIVERY_STREAM_ENABLED = true with RR_WORKER_DELIVERY_STREAM_ENABLED=false")
+	}
+}
+
+func TestLoopIntervalFallsBackWhenNotPositive(t *testing.T) {
+	for _, value := range []string{"0", "-5"} {
+		t.Setenv("RR_WORKER_DIGEST_INTERVAL_SECONDS", value)
+		if got := loopInterval("DIGEST_INTERVAL_SECONDS", time.Second, defaultDigestInterval); got != defaultDigestInterval {
+			t.Errorf("DIGEST_INTERVAL_SECONDS=%s: interval = %s, want %s", value, got, defaultDigestInterval)
+		}
+	}
+
+	t.Setenv("RR_WORKER_RECONCILE_INTERVAL_MINUTES", "3")
+	if got := loopInterval("RECONCILE_INTERVAL_MINUTES", time.Minute, defaultReconcileInterval); got != 3*time.Minute {
+		t.Errorf("RECONCILE_INTERVAL_MINUTES=3: interval = %s, want 3m0s", got)
+	}
+}
+
+func TestLoopIntervalDefaults(t *testing.T) {
+	if got := loopInterval("DIGEST_INTERVAL_SECONDS", time.Second, 0); got != defaultDigestInterval {
+		t.Errorf("digest interval = %s, want %s", got, defaultDigestInterval)
+	}
+	if got := loopInterval("RECONCILE_INTERVAL_MINUTES", time.Minute, 0); got != defaultReconcileInterval {
+		t.Errorf("reconcile interval = %s, want %s", got, defaultReconcileInterval)
+	}
+}
@@ -0,0 +1,160 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 2 * time.Second
+)
+
+var webhookPathPattern = regexp.MustCompile(`^/api/webhooks/\d+/[\w-]+$`)
+
+type notifier struct {
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// resetAt holds, per webhook, when an exhausted rate-limit bucket refills.
+	resetAt map[string]time.Time
+}
+
+// NewNotifier returns a notify.Notifier for "discord:<webhook URL>" channels.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient, resetAt: make(map[string]time.Time)}
+}
+
+func (n *notifier) Validate(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "https" || (u.Host != "discord.com" && u.Host != "discordapp.com") || !webhookPathPattern.MatchString(u.Path) {
+		return fmt.Errorf("%q is not a Discord webhook URL", webhookURL)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, webhookURL string, msg *notify.Message) (notify.Result, error) {
+	payload, err := json.Marshal(buildPayload(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode discord payload: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Wait out a bucket that an earlier request emptied
+		if wait := n.waitFor(webhookURL); wait > notify.MaxRetryAfter {
+			return notify.Result{}, &notify.RetryAfterError{After: wait, Err: fmt.Errorf("discord client error: webhook bucket exhausted")}
+		} else if wait > 0 {
+			logger.L().Sugar().Debugf("discord webhook bucket exhausted, waiting %s", wait)
+			if err := notify.Sleep(ctx, wait); err != nil {
+				return notify.Result{}, err
+			}
+		}
+
+		result, retryAfter, err := n.post(ctx, webhookURL, payload)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("discord webhook rate limited, retrying in %s", retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+// post sends one webhook request. A non-zero duration means Discord rate-limited the request
+// and asked us to retry after it.
+func (n *notifier) post(ctx context.Context, webhookURL string, payload []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("discord client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	n.trackBucket(webhookURL, resp.Header)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return result, retryAfter(resp.Header, body), fmt.Errorf("discord client error: rate limited")
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized:
+		// "Unknown Webhook" or "Invalid Webhook Token": the webhook was deleted or regenerated
+		return result, 0, fmt.Errorf("discord client error: %w: status %d: %s", notify.ErrGone, resp.StatusCode, bytes.TrimSpace(body))
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return result, 0, fmt.Errorf("discord client error: %w: status %d: %s", notify.ErrPermanent, resp.StatusCode, bytes.TrimSpace(body))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return result, 0, fmt.Errorf("discord client error: status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return result, 0, nil
+}
+
+// trackBucket remembers when the webhook's bucket refills if this request emptied it,
+// based on the X-RateLimit-Remaining and X-RateLimit-Reset-After headers.
+func (n *notifier) trackBucket(webhookURL string, header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	resetAfter, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64)
+	if err != nil || resetAfter <= 0 {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.resetAt[webhookURL] = time.Now().Add(time.Duration(resetAfter * float64(time.Second)))
+}
+
+func (n *notifier) waitFor(webhookURL string) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	resetAt, ok := n.resetAt[webhookURL]
+	if !ok {
+		return 0
+	}
+	wait := time.Until(resetAt)
+	if wait <= 0 {
+		delete(n.resetAt, webhookURL)
+		return 0
+	}
+	return wait
+}
+
+// retryAfter prefers the precise retry_after from a 429 body over the Retry-After header.
+func retryAfter(header http.Header, body []byte) time.Duration {
+	var rateLimit struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(body, &rateLimit); err == nil && rateLimit.RetryAfter > 0 {
+		return time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	}
+	return notify.RetryAfter(header, defaultRetryAfter)
+}
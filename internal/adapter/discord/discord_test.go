@@ -0,0 +1,74 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+)
+
+func TestMain(m *testing.M) {
+	notifytest.Main(m)
+}
+
+func testMessage() *notify.Message {
+	return notifytest.Message("")
+}
+
+func TestSendRetriesAfter429(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"message":"You are being rate limited.","retry_after":0.1,"global":false}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if _, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"message":"You are being rate limited.","retry_after":3600,"global":false}`)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != time.Hour {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 1h", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1: a long wait is left to the notifier", n)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusBadGateway, Body: "bad gateway"},
+		{Name: "invalid body", Status: http.StatusBadRequest, Body: `{"message":"Cannot send an empty message","code":50006}`, Permanent: true},
+		{Name: "unknown webhook", Status: http.StatusNotFound, Body: `{"message":"Unknown Webhook","code":10015}`, Permanent: true, Gone: true},
+		{Name: "invalid token", Status: http.StatusUnauthorized, Body: `{"message":"Invalid Webhook Token","code":50027}`, Permanent: true, Gone: true},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		_, err := NewNotifier(http.DefaultClient).Send(context.Background(), serverURL, testMessage())
+		return err
+	})
+}
@@ -0,0 +1,139 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+// Embed limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxAuthorLength      = 256
+)
+
+// Embed colours by release type
+const (
+	colorMajor      = 0xE74C3C
+	colorMinor      = 0x3498DB
+	colorPatch      = 0x2ECC71
+	colorPrerelease = 0xF1C40F
+	colorOther      = 0x95A5A6
+)
+
+type payload struct {
+	Username string  `json:"username"`
+	Embeds   []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string  `json:"title"`
+	URL         string  `json:"url,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color"`
+	Author      *author `json:"author,omitempty"`
+	Fields      []field `json:"fields,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+type author struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func buildPayload(msg *notify.Message) payload {
+	repoName := "unknown repository"
+	repoURL := ""
+	if msg.Repo != nil {
+		repoName = msg.Repo.Owner + "/" + msg.Repo.Name
+		repoURL = "https://github.com/" + repoName
+	}
+	repoAuthor := &author{Name: notify.Excerpt(repoName, maxAuthorLength), URL: repoURL}
+
+	if msg.Kind == domain.DeliveryKindDigest {
+		return payload{Username: "ReleaseRadar", Embeds: []embed{{
+			Title:       notify.DigestHeadline(len(msg.Digest)),
+			Description: notify.Excerpt(msg.Text, maxDescriptionLength),
+			Color:       colorOther,
+		}}}
+	}
+	if msg.Release == nil {
+		return payload{Username: "ReleaseRadar", Embeds: []embed{{
+			Title:       "Repository no longer tracked",
+			Description: fmt.Sprintf("%s no longer exists on GitHub, so ReleaseRadar stopped polling it.", repoName),
+			Color:       colorOther,
+			Author:      repoAuthor,
+		}}}
+	}
+
+	release := msg.Release
+	title := release.Title
+	if title == "" {
+		title = release.Tag
+	}
+
+	e := embed{
+		Title:  notify.Excerpt(title, maxTitleLength),
+		URL:    release.URL,
+		Color:  colorFor(release.Type()),
+		Author: repoAuthor,
+		Fields: []field{
+			{Name: "Tag", Value: "`" + release.Tag + "`", Inline: true},
+			{Name: "Type", Value: string(release.Type()), Inline: true},
+		},
+	}
+	if !release.PublishedAt.IsZero() {
+		// Discord renders <t:unix:f> in each reader's locale and timezone
+		e.Fields = append(e.Fields, field{Name: "Published", Value: fmt.Sprintf("<t:%d:f>", release.PublishedAt.Unix()), Inline: true})
+		e.Timestamp = release.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	if release.Author != "" {
+		e.Fields = append(e.Fields, field{Name: "Author", Value: fmt.Sprintf("[%s](https://github.com/%s)", release.Author, release.Author), Inline: true})
+	}
+
+	switch msg.Kind {
+	case domain.DeliveryKindReleaseUpdated:
+		e.Title = notify.Excerpt("Updated: "+title, maxTitleLength)
+		if msg.Revision != nil {
+			e.Description = msg.Revision.Summary
+		}
+	case domain.DeliveryKindReleaseDeleted:
+		e.Title = notify.Excerpt("Retracted: "+title, maxTitleLength)
+		e.URL = ""
+		e.Color = colorOther
+		e.Description = "This release is no longer published on GitHub."
+	default:
+		e.Description = notify.Excerpt(strings.TrimSpace(release.Body), maxDescriptionLength)
+	}
+	if body := strings.TrimSpace(msg.Text); body != "" {
+		// The subscription's template replaces the default description
+		e.Description = notify.Excerpt(body, maxDescriptionLength)
+	}
+
+	return payload{Username: "ReleaseRadar", Embeds: []embed{e}}
+}
+
+func colorFor(releaseType domain.ReleaseType) int {
+	switch releaseType {
+	case domain.ReleaseTypeMajor:
+		return colorMajor
+	case domain.ReleaseTypeMinor:
+		return colorMinor
+	case domain.ReleaseTypePatch:
+		return colorPatch
+	case domain.ReleaseTypePrerelease:
+		return colorPrerelease
+	default:
+		return colorOther
+	}
+}
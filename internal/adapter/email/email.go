@@ -0,0 +1,116 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+const sendTimeout = 30 * time.Second
+
+// Config holds SMTP settings and what is needed to build unsubscribe links.
+type Config struct {
+	Host     string
+	Port     int
+	Username string // Empty disables SMTP AUTH, e.g. for a local relay
+	Password string
+	From     string
+
+	PublicBaseURL     string // Base URL of the API, e.g. "https://radar.example.com"
+	UnsubscribeSecret []byte // Key for signing unsubscribe tokens; shared with the API
+}
+
+type notifier struct {
+	cfg Config
+}
+
+// NewNotifier returns a notify.Notifier for "email:<address>" channels.
+func NewNotifier(cfg Config) notify.Notifier {
+	return &notifier{cfg: cfg}
+}
+
+func (n *notifier) Validate(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid email address: %w", err)
+	}
+	if parsed.Name != "" || parsed.Address != address {
+		return fmt.Errorf("%q must be a bare email address", address)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, address string, msg *notify.Message) (notify.Result, error) {
+	content, err := n.compose(address, msg)
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to compose email: %w", err)
+	}
+	if err := n.send(ctx, address, content); err != nil {
+		return notify.Result{}, fmt.Errorf("email client error: %w", err)
+	}
+	return notify.Result{}, nil
+}
+
+// send delivers one message over SMTP, upgrading to TLS when the server offers STARTTLS.
+func (n *notifier) send(ctx context.Context, to string, content []byte) error {
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+
+	dialer := &net.Dialer{Timeout: sendTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	deadline := time.Now().Add(sendTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("starttls failed: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", n.cfg.From, err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,268 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/signedtoken"
+)
+
+var testSecret = []byte("unsubscribe-secret")
+
+// smtpServer is a minimal SMTP stand-in that accepts one message per connection without
+// STARTTLS or AUTH, and records the envelope and content of the last one.
+type smtpServer struct {
+	listener  net.Listener
+	rcptReply string // Reply to RCPT TO; "250 OK" unless set
+
+	from, to string
+	data     chan string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpServer{listener: listener, rcptReply: "250 OK", data: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpServer) config() Config {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return Config{
+		Host:              "127.0.0.1",
+		Port:              addr.Port,
+		From:              "ReleaseRadar <noreply@radar.test>",
+		PublicBaseURL:     "https://radar.test/",
+		UnsubscribeSecret: testSecret,
+	}
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 smtp.test ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-smtp.test")
+			reply("250 8BITMIME")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.to = line
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data <- data.String()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func testMessage(subscriptionID uuid.UUID) *notify.Message {
+	return &notify.Message{
+		Kind: domain.DeliveryKindRelease,
+		Repo: &domain.Repo{Owner: "golang", Name: "go"},
+		Release: &domain.Release{
+			Tag:   "v1.2.0",
+			Title: "Go 1.2",
+			URL:   "https://github.com/golang/go/releases/tag/v1.2.0",
+			Body:  "Faster <builds> & smaller binaries",
+		},
+		Subscription: &domain.Subscription{ID: subscriptionID},
+	}
+}
+
+// parts returns the decoded bodies of a multipart/alternative message by content type.
+func parts(t *testing.T, message *mail.Message) map[string]string {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parse Content-Type: %v", err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+
+	bodies := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		// NextPart undoes the quoted-printable encoding
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part body: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(body)
+	}
+	return bodies
+}
+
+func TestSend(t *testing.T) {
+	server := newSMTPServer(t)
+	subscriptionID := uuid.New()
+
+	result, err := NewNotifier(server.config()).Send(context.Background(), "dev@example.com", testMessage(subscriptionID))
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != 0 {
+		t.Errorf("StatusCode = %d, want 0 for SMTP", result.StatusCode)
+	}
+
+	var data string
+	select {
+	case data = <-server.data:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP server received no message")
+	}
+	if !strings.HasPrefix(server.from, "MAIL FROM:<noreply@radar.test>") {
+		t.Errorf("envelope sender = %q", server.from)
+	}
+	if server.to != "RCPT TO:<dev@example.com>" {
+		t.Errorf("envelope recipient = %q", server.to)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "New release: golang/go v1.2.0: Go 1.2" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	if got := message.Header.Get("To"); got != "<dev@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+
+	// RFC 8058 one-click unsubscribe, signed for this subscription only
+	listUnsubscribe := message.Header.Get("List-Unsubscribe")
+	if !strings.HasPrefix(listUnsubscribe, "<https://radar.test"+UnsubscribePath+"?token=") || !strings.HasSuffix(listUnsubscribe, ">") {
+		t.Fatalf("List-Unsubscribe = %q", listUnsubscribe)
+	}
+	if got := message.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+	link := strings.Trim(listUnsubscribe, "<>")
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse unsubscribe link: %v", err)
+	}
+	id, err := ParseUnsubscribeToken(testSecret, u.Query().Get("token"))
+	if err != nil || id != subscriptionID {
+		t.Errorf("unsubscribe token names %s (%v), want %s", id, err, subscriptionID)
+	}
+	if _, err := ParseUnsubscribeToken([]byte("other-secret"), u.Query().Get("token")); !errors.Is(err, signedtoken.ErrInvalidToken) {
+		t.Errorf("token verified with another secret: %v", err)
+	}
+
+	bodies := parts(t, message)
+	text, html := bodies["text/plain"], bodies["text/html"]
+	for _, want := range []string{
+		"New release: golang/go v1.2.0",
+		"Tag:        v1.2.0",
+		"Faster <builds> & smaller binaries",
+		"Unsubscribe from golang/go: " + link,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text part does not contain %q:\n%s", want, text)
+		}
+	}
+	for _, want := range []string{
+		"<h2>New release: golang/go v1.2.0</h2>",
+		"Faster &lt;builds&gt; &amp; smaller binaries",
+		`<a href="https://github.com/golang/go/releases/tag/v1.2.0">View release on GitHub</a>`,
+		`<a href="` + strings.ReplaceAll(link, "&", "&amp;") + `">Unsubscribe from golang/go</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part does not contain %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestSendWithoutSubscriptionHasNoUnsubscribeLink(t *testing.T) {
+	server := newSMTPServer(t)
+	msg := testMessage(uuid.New())
+	msg.Subscription = nil
+
+	if _, err := NewNotifier(server.config()).Send(context.Background(), "dev@example.com", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(<-server.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := message.Header.Get("List-Unsubscribe"); got != "" {
+		t.Errorf("List-Unsubscribe = %q, want none", got)
+	}
+}
+
+func TestSendRejectedRecipient(t *testing.T) {
+	server := newSMTPServer(t)
+	server.rcptReply = "550 No such user"
+
+	_, err := NewNotifier(server.config()).Send(context.Background(), "nobody@example.com", testMessage(uuid.New()))
+	if err == nil || !strings.Contains(err.Error(), "No such user") {
+		t.Fatalf("Send error = %v, want the server's rejection", err)
+	}
+}
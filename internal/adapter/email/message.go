@@ -0,0 +1,154 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+const maxNotesLength = 5000
+
+// mailData is what the plain-text and HTML templates render.
+type mailData struct {
+	notify.Summary
+	Release        *domain.Release
+	Type           string // Empty when the release type is unknown
+	UnsubscribeURL string
+}
+
+var textBody = template.Must(template.New("text").Parse(`{{.Headline}}
+{{with .Release}}
+Repository: {{$.RepoName}}
+Tag:        {{.Tag}}
+Title:      {{$.Title}}
+{{with $.Type}}Type:       {{.}}
+{{end}}{{if not $.Published.IsZero}}Published:  {{$.Published.UTC.Format "2006-01-02 15:04 UTC"}}
+{{end}}{{with $.Author}}Author:     {{.}}
+{{end}}{{with $.Link}}Link:       {{.}}
+{{end}}{{end}}{{with .Details}}
+{{.}}
+{{end}}{{with .UnsubscribeURL}}
+--
+Unsubscribe from {{$.RepoName}}: {{.}}
+{{end}}`))
+
+var htmlBody = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif; line-height: 1.4">
+<h2>{{.Headline}}</h2>
+{{with .Release}}<table cellpadding="4">
+<tr><td><b>Repository</b></td><td>{{if $.RepoURL}}<a href="{{$.RepoURL}}">{{$.RepoName}}</a>{{else}}{{$.RepoName}}{{end}}</td></tr>
+<tr><td><b>Tag</b></td><td><code>{{.Tag}}</code></td></tr>
+<tr><td><b>Title</b></td><td>{{$.Title}}</td></tr>
+{{with $.Type}}<tr><td><b>Type</b></td><td>{{.}}</td></tr>{{end}}
+{{if not $.Published.IsZero}}<tr><td><b>Published</b></td><td>{{$.Published.UTC.Format "2006-01-02 15:04 UTC"}}</td></tr>{{end}}
+{{with $.Author}}<tr><td><b>Author</b></td><td><a href="{{$.AuthorURL}}">{{.}}</a></td></tr>{{end}}
+</table>{{end}}
+{{with .Details}}<pre style="white-space: pre-wrap">{{.}}</pre>{{end}}
+{{with .Link}}<p><a href="{{.}}">View release on GitHub</a></p>{{end}}
+{{with .UnsubscribeURL}}<hr><p style="font-size: small; color: #666"><a href="{{.}}">Unsubscribe from {{$.RepoName}}</a></p>{{end}}
+</body></html>
+`))
+
+// compose renders a complete RFC 5322 message with plain-text and HTML alternatives.
+func (n *notifier) compose(to string, msg *notify.Message) ([]byte, error) {
+	data := n.mailData(msg)
+
+	var text, html bytes.Buffer
+	if err := textBody.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := htmlBody.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&out, "%s: %s\r\n", key, value) }
+	header("From", n.cfg.From)
+	header("To", (&mail.Address{Address: to}).String())
+	header("Subject", mime.QEncoding.Encode("utf-8", subject(data)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", n.messageID())
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	if data.UnsubscribeURL != "" {
+		// RFC 8058 one-click unsubscribe
+		header("List-Unsubscribe", "<"+data.UnsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func (n *notifier) mailData(msg *notify.Message) mailData {
+	summary := notify.Summarize(msg)
+	data := mailData{Summary: summary, Release: msg.Release}
+	if msg.Subscription != nil && n.cfg.PublicBaseURL != "" && len(n.cfg.UnsubscribeSecret) > 0 {
+		data.UnsubscribeURL = UnsubscribeURL(strings.TrimRight(n.cfg.PublicBaseURL, "/"), n.cfg.UnsubscribeSecret, msg.Subscription.ID)
+	}
+	if summary.Type != domain.ReleaseTypeUnknown {
+		data.Type = string(summary.Type)
+	}
+	data.Details = notify.Excerpt(strings.TrimSpace(summary.Details), maxNotesLength)
+	return data
+}
+
+func subject(data mailData) string {
+	if data.Release != nil && data.Title != data.Release.Tag {
+		return data.Headline + ": " + data.Title
+	}
+	return data.Headline
+}
+
+func (n *notifier) messageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	domainPart := "releaseradar"
+	if from, err := mail.ParseAddress(n.cfg.From); err == nil {
+		if _, host, ok := strings.Cut(from.Address, "@"); ok {
+			domainPart = host
+		}
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domainPart + ">"
+}
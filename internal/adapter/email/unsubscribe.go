@@ -0,0 +1,34 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/pkg/signedtoken"
+)
+
+// UnsubscribePath is the API route that handles unsubscribe links.
+const UnsubscribePath = "/api/v1/unsubscribe"
+
+// UnsubscribeToken signs a subscription ID for use in one-click unsubscribe links.
+func UnsubscribeToken(secret []byte, subscriptionID uuid.UUID) string {
+	return signedtoken.Sign(secret, subscriptionID[:])
+}
+
+// ParseUnsubscribeToken verifies a token made by UnsubscribeToken and returns the subscription ID.
+func ParseUnsubscribeToken(secret []byte, token string) (uuid.UUID, error) {
+	payload, err := signedtoken.Verify(secret, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id, err := uuid.FromBytes(payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", signedtoken.ErrInvalidToken, err)
+	}
+	return id, nil
+}
+
+// UnsubscribeURL builds the link that removes a subscription, e.g. for List-Unsubscribe.
+func UnsubscribeURL(baseURL string, secret []byte, subscriptionID uuid.UUID) string {
+	return baseURL + UnsubscribePath + "?token=" + UnsubscribeToken(secret, subscriptionID)
+}
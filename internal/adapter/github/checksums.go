@@ -0,0 +1,66 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"strings"
+)
+
+// IsChecksumFile reports whether a release asset looks like a SHA-256 checksums listing,
+// e.g. SHA256SUMS, checksums.txt or GoReleaser's <project>_<version>_checksums.txt.
+func IsChecksumFile(name string) bool {
+	lower := strings.ToLower(name)
+	switch lower {
+	case "sha256sums", "sha256sums.txt", "checksums.txt", "checksums.sha256":
+		return true
+	}
+	return strings.HasSuffix(lower, "_checksums.txt") || strings.HasSuffix(lower, "-checksums.txt")
+}
+
+// ParseChecksums extracts SHA-256 digests keyed by file name. It understands the GNU coreutils
+// format ("<digest>  <name>" or "<digest> *<name>") and the BSD format ("SHA256 (<name>) = <digest>").
+// Lines that do not carry a valid SHA-256 digest are skipped.
+func ParseChecksums(data []byte) map[string]string {
+	digests := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var digest, name string
+		if rest, ok := strings.CutPrefix(line, "SHA256 ("); ok {
+			n, d, found := strings.Cut(rest, ") = ")
+			if !found {
+				continue
+			}
+			name, digest = n, d
+		} else {
+			d, n, found := strings.Cut(line, " ")
+			if !found {
+				continue
+			}
+			digest = d
+			name = strings.TrimPrefix(strings.TrimSpace(n), "*")
+		}
+
+		digest = strings.ToLower(strings.TrimSpace(digest))
+		if !isSHA256(digest) || name == "" {
+			continue
+		}
+		digests[name] = digest
+	}
+
+	return digests
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
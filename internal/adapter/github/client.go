@@ -2,17 +2,53 @@ package github
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound is returned when GitHub reports that a repository does not exist (or is no longer accessible).
+var ErrNotFound = errors.New("github: repository not found")
+
 type Release struct {
 	Tag         string
 	Title       string
 	URL         string
 	PublishedAt time.Time
 	Body        string // For calculating hash
+	Prerelease  bool
+	Author      string // GitHub login of the publisher
+	Assets      []Asset
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name          string
+	Size          int64
+	ContentType   string
+	DownloadURL   string
+	DownloadCount int
 }
 
+// Repository is the GitHub-side identity and state of a repository. ID stays the same across
+// renames and transfers, so Owner and Name reflect the repository's current location.
+type Repository struct {
+	ID       int64
+	Owner    string
+	Name     string
+	Archived bool
+}
+
+// ReleasesPageSize is the maximum number of releases returned by ListReleases.
+const ReleasesPageSize = 100
+
 type Client interface {
 	GetLatestRelease(ctx context.Context, owner, repo string, etag string) (*Release, string, error)
+	// ListReleases returns the most recent published releases, newest first, up to ReleasesPageSize,
+	// and the list's ETag. Releases are nil when the list is unchanged since etag.
+	ListReleases(ctx context.Context, owner, repo string, etag string) ([]Release, string, error)
+	// GetRepository follows renames and transfers; it returns ErrNotFound for deleted repositories.
+	GetRepository(ctx context.Context, owner, repo string) (*Repository, error)
+	GetRepositoryByID(ctx context.Context, id int64) (*Repository, error)
+	// DownloadAsset fetches the content of a release asset, refusing anything larger than maxBytes.
+	DownloadAsset(ctx context.Context, url string, maxBytes int64) ([]byte, error)
 }
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -26,12 +27,17 @@ func (g *githubClient) GetLatestRelease(ctx context.Context, owner, repo string,
 	var newETag string
 
 	err := retry.Do(3, 2*time.Second, func() error {
-		rel, resp, err := g.client.Repositories.GetLatestRelease(ctx, owner, repo)
+		var rel *gh.RepositoryRelease
+		resp, err := g.getConditional(ctx, fmt.Sprintf("repos/%s/%s/releases/latest", owner, repo), etag, &rel)
 		if err != nil {
 			if resp != nil && resp.StatusCode == http.StatusNotModified {
 				// Content not modified, return nil release and original etag
 				return nil
 			}
+			if isNotFound(resp) {
+				// The repository has no published releases yet
+				return nil
+			}
 			logger.L().Sugar().Errorf("failed to get latest release for %s/%s: %v", owner, repo, err)
 			return fmt.Errorf("github client error: %w", err)
 		}
@@ -41,13 +47,7 @@ func (g *githubClient) GetLatestRelease(ctx context.Context, owner, repo string,
 		}
 
 		if rel != nil {
-			latestRelease = &Release{
-				Tag:         rel.GetTagName(),
-				Title:       rel.GetName(),
-				URL:         rel.GetHTMLURL(),
-				PublishedAt: rel.GetPublishedAt().Time,
-				Body:        rel.GetBody(),
-			}
+			latestRelease = toRelease(rel)
 		}
 		return nil
 	})
@@ -58,3 +58,161 @@ func (g *githubClient) GetLatestRelease(ctx context.Context, owner, repo string,
 
 	return latestRelease, newETag, nil
 }
+
+func (g *githubClient) ListReleases(ctx context.Context, owner, repo string, etag string) ([]Release, string, error) {
+	var releases []Release
+	var newETag string
+
+	err := retry.Do(3, 2*time.Second, func() error {
+		var rels []*gh.RepositoryRelease
+		resp, err := g.getConditional(ctx, fmt.Sprintf("repos/%s/%s/releases?per_page=%d", owner, repo, ReleasesPageSize), etag, &rels)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotModified {
+				// Unchanged since etag; nil releases tell the caller so
+				releases, newETag = nil, etag
+				return nil
+			}
+			logger.L().Sugar().Errorf("failed to list releases for %s/%s: %v", owner, repo, err)
+			return fmt.Errorf("github client error: %w", err)
+		}
+
+		newETag = resp.Header.Get("Etag")
+		releases = make([]Release, 0, len(rels))
+		for _, rel := range rels {
+			if rel.GetDraft() {
+				continue
+			}
+			releases = append(releases, *toRelease(rel))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return releases, newETag, nil
+}
+
+// getConditional GETs an API path into v, sending etag as If-None-Match so that an unchanged
+// resource is answered with 304 Not Modified, which GitHub does not count against the rate limit.
+func (g *githubClient) getConditional(ctx context.Context, path, etag string, v interface{}) (*gh.Response, error) {
+	req, err := g.client.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return g.client.Do(ctx, req, v)
+}
+
+func (g *githubClient) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	return g.getRepository(fmt.Sprintf("%s/%s", owner, repo), func() (*gh.Repository, *gh.Response, error) {
+		return g.client.Repositories.Get(ctx, owner, repo)
+	})
+}
+
+func (g *githubClient) GetRepositoryByID(ctx context.Context, id int64) (*Repository, error) {
+	return g.getRepository(fmt.Sprintf("#%d", id), func() (*gh.Repository, *gh.Response, error) {
+		return g.client.Repositories.GetByID(ctx, id)
+	})
+}
+
+func (g *githubClient) getRepository(ref string, fetch func() (*gh.Repository, *gh.Response, error)) (*Repository, error) {
+	var repository *Repository
+	notFound := false
+
+	err := retry.Do(3, 2*time.Second, func() error {
+		r, resp, err := fetch()
+		if err != nil {
+			if isNotFound(resp) {
+				notFound = true
+				return nil
+			}
+			logger.L().Sugar().Errorf("failed to get repository %s: %v", ref, err)
+			return fmt.Errorf("github client error: %w", err)
+		}
+
+		repository = &Repository{
+			ID:       r.GetID(),
+			Owner:    r.GetOwner().GetLogin(),
+			Name:     r.GetName(),
+			Archived: r.GetArchived(),
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if notFound {
+		return nil, ErrNotFound
+	}
+
+	return repository, nil
+}
+
+func (g *githubClient) DownloadAsset(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	var content []byte
+
+	err := retry.Do(3, 2*time.Second, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create asset request: %w", err)
+		}
+
+		resp, err := g.client.Client().Do(req)
+		if err != nil {
+			logger.L().Sugar().Errorf("failed to download asset %s: %v", url, err)
+			return fmt.Errorf("github client error: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("github client error: unexpected status %d downloading %s", resp.StatusCode, url)
+		}
+
+		content, err = io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+		if err != nil {
+			return fmt.Errorf("failed to read asset %s: %w", url, err)
+		}
+		if int64(len(content)) > maxBytes {
+			return fmt.Errorf("asset %s exceeds %d bytes", url, maxBytes)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func toRelease(rel *gh.RepositoryRelease) *Release {
+	release := &Release{
+		Tag:         rel.GetTagName(),
+		Title:       rel.GetName(),
+		URL:         rel.GetHTMLURL(),
+		PublishedAt: rel.GetPublishedAt().Time,
+		Body:        rel.GetBody(),
+		Prerelease:  rel.GetPrerelease(),
+		Author:      rel.GetAuthor().GetLogin(),
+	}
+	for _, asset := range rel.Assets {
+		release.Assets = append(release.Assets, Asset{
+			Name:          asset.GetName(),
+			Size:          int64(asset.GetSize()),
+			ContentType:   asset.GetContentType(),
+			DownloadURL:   asset.GetBrowserDownloadURL(),
+			DownloadCount: asset.GetDownloadCount(),
+		})
+	}
+	return release
+}
+
+// isNotFound reports whether GitHub answered with 404 Not Found or 410 Gone.
+func isNotFound(resp *gh.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone)
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gh "github.com/google/go-github/v63/github"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *githubClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := gh.NewClient(server.Client())
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return &githubClient{client: client}
+}
+
+func TestListReleasesIsConditional(t *testing.T) {
+	const etag = `W/"releases-1"`
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/golang/go/releases" {
+			t.Errorf("requested %s", r.URL.Path)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Etag", etag)
+		_, _ = io.WriteString(w, `[{"tag_name":"v1.2.0","name":"Go 1.2"},{"tag_name":"v1.3.0-rc1","draft":true}]`)
+	})
+
+	releases, newETag, err := client.ListReleases(context.Background(), "golang", "go", "")
+	if err != nil {
+		t.Fatalf("ListReleases: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Tag != "v1.2.0" || newETag != etag {
+		t.Fatalf("ListReleases = %+v, %q; want v1.2.0 without the draft, and the ETag", releases, newETag)
+	}
+
+	releases, newETag, err = client.ListReleases(context.Background(), "golang", "go", etag)
+	if err != nil {
+		t.Fatalf("conditional ListReleases: %v", err)
+	}
+	if releases != nil || newETag != etag {
+		t.Errorf("conditional ListReleases = %+v, %q; want nil releases and the same ETag", releases, newETag)
+	}
+}
+
+func TestGetLatestReleaseIsConditional(t *testing.T) {
+	const etag = `W/"latest-1"`
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Etag", etag)
+		_, _ = io.WriteString(w, `{"tag_name":"v1.2.0","name":"Go 1.2"}`)
+	})
+
+	release, newETag, err := client.GetLatestRelease(context.Background(), "golang", "go", "")
+	if err != nil || release == nil || release.Tag != "v1.2.0" || newETag != etag {
+		t.Fatalf("GetLatestRelease = %+v, %q, %v", release, newETag, err)
+	}
+	release, _, err = client.GetLatestRelease(context.Background(), "golang", "go", etag)
+	if err != nil || release != nil {
+		t.Errorf("conditional GetLatestRelease = %+v, %v; want no release", release, err)
+	}
+}
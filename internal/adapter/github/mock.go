@@ -18,3 +18,39 @@ func (m *MockGitHubClient) GetLatestRelease(ctx context.Context, owner, repo, et
 	}
 	return r, args.String(1), args.Error(2)
 }
+
+func (m *MockGitHubClient) ListReleases(ctx context.Context, owner, repo, etag string) ([]Release, string, error) {
+	args := m.Called(ctx, owner, repo, etag)
+	var r []Release
+	if args.Get(0) != nil {
+		r = args.Get(0).([]Release)
+	}
+	return r, args.String(1), args.Error(2)
+}
+
+func (m *MockGitHubClient) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	args := m.Called(ctx, owner, repo)
+	var r *Repository
+	if args.Get(0) != nil {
+		r = args.Get(0).(*Repository)
+	}
+	return r, args.Error(1)
+}
+
+func (m *MockGitHubClient) GetRepositoryByID(ctx context.Context, id int64) (*Repository, error) {
+	args := m.Called(ctx, id)
+	var r *Repository
+	if args.Get(0) != nil {
+		r = args.Get(0).(*Repository)
+	}
+	return r, args.Error(1)
+}
+
+func (m *MockGitHubClient) DownloadAsset(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	args := m.Called(ctx, url, maxBytes)
+	var b []byte
+	if args.Get(0) != nil {
+		b = args.Get(0).([]byte)
+	}
+	return b, args.Error(1)
+}
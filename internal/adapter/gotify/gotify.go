@@ -0,0 +1,155 @@
+package gotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+const (
+	// Gotify's default priority at which Android clients raise a notification
+	defaultPriority   = 5
+	maxDetailsLength  = 4000
+	defaultRetryAfter = 10 * time.Second
+)
+
+type notifier struct {
+	httpClient *http.Client
+	lookupIP   notify.LookupIPFunc
+}
+
+// NewNotifier returns a notify.Notifier for "gotify:<server URL>?token=<application token>"
+// channels, e.g. "gotify:https://push.example.com?token=AbCdEf". Servers must be publicly
+// reachable; httpClient should come from notify.NewPublicClient.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient, lookupIP: net.DefaultResolver.LookupIPAddr}
+}
+
+func (n *notifier) Validate(address string) error {
+	if _, _, err := splitAddress(address); err != nil {
+		return err
+	}
+	u, _ := url.Parse(address)
+	// Sending re-checks the address it connects to; this catches the obvious cases up front
+	return notify.CheckPublicHost(u.Hostname(), n.lookupIP)
+}
+
+// splitAddress returns the message endpoint and the application token of a channel address.
+func splitAddress(address string) (string, string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", fmt.Errorf("%q is not a Gotify server URL", address)
+	}
+	token := u.Query().Get("token")
+	if token == "" {
+		return "", "", fmt.Errorf("gotify address needs an application token, e.g. %s?token=...", u.Scheme+"://"+u.Host)
+	}
+
+	u.RawQuery = ""
+	u.Path = strings.TrimRight(u.Path, "/") + "/message"
+	return u.String(), token, nil
+}
+
+type message struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+func (n *notifier) Send(ctx context.Context, address string, msg *notify.Message) (notify.Result, error) {
+	endpoint, token, err := splitAddress(address)
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("%w: %v", notify.ErrPermanent, err)
+	}
+
+	payload, err := json.Marshal(buildMessage(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode gotify message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to create gotify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Header rather than query parameter, so the token stays out of proxy access logs
+	req.Header.Set("X-Gotify-Key", token)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("gotify client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return result, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var apiErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"errorDescription"`
+	}
+	detail := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.ErrorDescription != "" {
+		detail = apiErr.ErrorDescription
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Only behind a rate-limiting proxy; the notifier retries once it allows
+		return result, &notify.RetryAfterError{After: notify.RetryAfter(resp.Header, defaultRetryAfter), Err: fmt.Errorf("gotify client error: rate limited")}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// The application token was deleted
+		return result, fmt.Errorf("gotify client error: %w: status %d: %s", notify.ErrGone, resp.StatusCode, detail)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// Malformed message, or not a Gotify server
+		return result, fmt.Errorf("gotify client error: %w: status %d: %s", notify.ErrPermanent, resp.StatusCode, detail)
+	}
+	return result, fmt.Errorf("gotify client error: status %d: %s", resp.StatusCode, detail)
+}
+
+func buildMessage(msg *notify.Message) message {
+	summary := notify.Summarize(msg)
+
+	var body strings.Builder
+	if msg.Release != nil {
+		body.WriteString(summary.Title)
+	}
+	if details := strings.TrimSpace(summary.Details); details != "" {
+		if body.Len() > 0 {
+			body.WriteString("\n\n")
+		}
+		body.WriteString(notify.Excerpt(details, maxDetailsLength))
+	}
+	if body.Len() == 0 {
+		body.WriteString(summary.Headline)
+	}
+
+	m := message{
+		Title:    summary.Headline,
+		Message:  body.String(),
+		Priority: defaultPriority,
+		Extras: map[string]any{
+			"client::display": map[string]string{"contentType": "text/plain"},
+		},
+	}
+	if summary.Link != "" {
+		m.Extras["client::notification"] = map[string]any{"click": map[string]string{"url": summary.Link}}
+	}
+	return m
+}
@@ -0,0 +1,111 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+)
+
+func testMessage() *notify.Message {
+	return notifytest.Message("Faster builds")
+}
+
+func TestSendMessage(t *testing.T) {
+	var path, key, query string
+	var got struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+		Extras   struct {
+			Display struct {
+				ContentType string `json:"contentType"`
+			} `json:"client::display"`
+			Notification struct {
+				Click struct {
+					URL string `json:"url"`
+				} `json:"click"`
+			} `json:"client::notification"`
+		} `json:"extras"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key, query = r.URL.Path, r.Header.Get("X-Gotify-Key"), r.URL.RawQuery
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, `{"id":1}`)
+	}))
+	defer server.Close()
+
+	result, err := NewNotifier(server.Client()).Send(context.Background(), server.URL+"/gotify/?token=AbCdEf", testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+	if path != "/gotify/message" || query != "" {
+		t.Errorf("posted to %s?%s, want /gotify/message without the token in the query", path, query)
+	}
+	if key != "AbCdEf" {
+		t.Errorf("X-Gotify-Key = %q, want the application token", key)
+	}
+
+	if got.Title != "New release: golang/go v1.2.0" || got.Message != "Go 1.2\n\nFaster builds" || got.Priority != defaultPriority {
+		t.Errorf("message = %+v", got)
+	}
+	if got.Extras.Display.ContentType != "text/plain" {
+		t.Errorf("client::display contentType = %q, want text/plain", got.Extras.Display.ContentType)
+	}
+	if got.Extras.Notification.Click.URL != "https://github.com/golang/go/releases/tag/v1.2.0" {
+		t.Errorf("client::notification click = %q", got.Extras.Notification.Click.URL)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	const body = `{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token"}`
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusInternalServerError, Body: body},
+		{Name: "rate limited", Status: http.StatusTooManyRequests, Body: body},
+		{Name: "bad request", Status: http.StatusBadRequest, Body: body, Permanent: true},
+		{Name: "not found", Status: http.StatusNotFound, Body: body, Permanent: true},
+		{Name: "deleted token", Status: http.StatusUnauthorized, Body: body, Permanent: true, Gone: true},
+		{Name: "forbidden", Status: http.StatusForbidden, Body: body, Permanent: true, Gone: true},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		_, err := NewNotifier(http.DefaultClient).Send(context.Background(), serverURL+"?token=AbCdEf", testMessage())
+		return err
+	})
+}
+
+func TestSendReturnsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL+"?token=AbCdEf", testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != 30*time.Second {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 30s", err)
+	}
+}
+
+func TestValidateRejectsInternalServers(t *testing.T) {
+	n := NewNotifier(http.DefaultClient)
+	for _, u := range []string{"http://127.0.0.1?token=AbCdEf", "http://10.0.0.7/gotify?token=AbCdEf", "http://169.254.169.254?token=AbCdEf"} {
+		if err := n.Validate(u); !errors.Is(err, notify.ErrPrivateAddress) {
+			t.Errorf("Validate(%q) = %v, want ErrPrivateAddress", u, err)
+		}
+	}
+	if err := n.Validate("https://203.0.113.10?token=AbCdEf"); err != nil {
+		t.Errorf("Validate of a public server: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package matrix
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+// Clients collapse long events poorly, so release notes are cut well below the 64 KiB event limit.
+const maxDetailsLength = 4000
+
+// content is an m.room.message event with an HTML rendering. m.notice marks it as sent by a
+// bot, which clients show less prominently and other bots must not answer.
+type content struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func buildContent(msg *notify.Message) content {
+	summary := notify.Summarize(msg)
+	details := strings.TrimSpace(notify.Excerpt(summary.Details, maxDetailsLength))
+
+	var plain, formatted strings.Builder
+	plain.WriteString(summary.Headline)
+	fmt.Fprintf(&formatted, "<strong>%s</strong>", html.EscapeString(summary.Headline))
+	if msg.Release != nil && summary.Title != msg.Release.Tag {
+		fmt.Fprintf(&plain, "\n%s", summary.Title)
+		fmt.Fprintf(&formatted, "<br>%s", html.EscapeString(summary.Title))
+	}
+	if details != "" {
+		fmt.Fprintf(&plain, "\n\n%s", details)
+		fmt.Fprintf(&formatted, "<blockquote>%s</blockquote>", strings.ReplaceAll(html.EscapeString(details), "\n", "<br>"))
+	}
+	if summary.Link != "" {
+		fmt.Fprintf(&plain, "\n%s", summary.Link)
+		fmt.Fprintf(&formatted, `<p><a href="%s">View release</a></p>`, html.EscapeString(summary.Link))
+	}
+
+	return content{
+		MsgType:       "m.notice",
+		Body:          plain.String(),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	}
+}
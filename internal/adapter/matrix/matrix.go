@@ -0,0 +1,133 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 5 * time.Second
+)
+
+// roomIDPattern matches room IDs such as "!abcdef:matrix.org". Aliases ("#room:server") need a
+// directory lookup and are not accepted.
+var roomIDPattern = regexp.MustCompile(`^![^:\s]+:[^\s]+$`)
+
+type notifier struct {
+	httpClient    *http.Client
+	homeserverURL string
+	accessToken   string
+}
+
+// NewNotifier returns a notify.Notifier for "matrix:<room ID>" channels. Messages are sent by
+// the account owning accessToken, which must have joined the rooms.
+func NewNotifier(httpClient *http.Client, homeserverURL, accessToken string) notify.Notifier {
+	return &notifier{
+		httpClient:    httpClient,
+		homeserverURL: strings.TrimRight(homeserverURL, "/"),
+		accessToken:   accessToken,
+	}
+}
+
+func (n *notifier) Validate(roomID string) error {
+	if !roomIDPattern.MatchString(roomID) {
+		return fmt.Errorf("%q is not a Matrix room ID like !room:example.org", roomID)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, roomID string, msg *notify.Message) (notify.Result, error) {
+	content, err := json.Marshal(buildContent(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode matrix event: %w", err)
+	}
+
+	// The homeserver deduplicates on the transaction ID, so resending a delivery cannot post twice
+	txnID := uuid.NewString()
+	if msg.Delivery != nil {
+		txnID = "rr-" + msg.Delivery.ID.String()
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", n.homeserverURL, url.PathEscape(roomID), url.PathEscape(txnID))
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, err := n.put(ctx, endpoint, content)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("matrix homeserver rate limited, retrying in %s", retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+// apiError is the standard error body of the client-server API.
+type apiError struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMs int64  `json:"retry_after_ms"`
+}
+
+// put sends one event. A non-zero duration means the homeserver rate-limited the request
+// and asked us to retry after it.
+func (n *notifier) put(ctx context.Context, endpoint string, content []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(content))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.accessToken)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("matrix client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return result, 0, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var apiErr apiError
+	_ = json.Unmarshal(body, &apiErr)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || apiErr.ErrCode == "M_LIMIT_EXCEEDED":
+		wait := notify.RetryAfter(resp.Header, defaultRetryAfter)
+		if apiErr.RetryAfterMs > 0 {
+			wait = time.Duration(apiErr.RetryAfterMs) * time.Millisecond
+		}
+		return result, wait, fmt.Errorf("matrix client error: rate limited")
+	case apiErr.ErrCode == "M_FORBIDDEN" || apiErr.ErrCode == "M_NOT_FOUND":
+		// Not joined to, banned from, or no such room
+		return result, 0, fmt.Errorf("matrix client error: %w: %s: %s", notify.ErrGone, apiErr.ErrCode, apiErr.Error)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// e.g. M_UNKNOWN_TOKEN: the access token was revoked, which no retry fixes
+		return result, 0, fmt.Errorf("matrix client error: %w: status %d: %s: %s", notify.ErrPermanent, resp.StatusCode, apiErr.ErrCode, apiErr.Error)
+	case apiErr.ErrCode != "":
+		return result, 0, fmt.Errorf("matrix client error: status %d: %s: %s", resp.StatusCode, apiErr.ErrCode, apiErr.Error)
+	}
+	return result, 0, fmt.Errorf("matrix client error: status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
@@ -0,0 +1,127 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+func TestMain(m *testing.M) {
+	notifytest.Main(m)
+}
+
+const roomID = "!abc:example.org"
+
+func testMessage() *notify.Message {
+	msg := notifytest.Message("Faster <builds>\nSmaller binaries")
+	msg.Delivery = &domain.Delivery{ID: uuid.MustParse("6f1d3c1e-8f1a-4a57-9a43-0d6f0b1b2c3d")}
+	return msg
+}
+
+func TestSendEvent(t *testing.T) {
+	var method, path, auth string
+	var got content
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, `{"event_id":"$1"}`)
+	}))
+	defer server.Close()
+
+	result, err := NewNotifier(server.Client(), server.URL+"/", "secret-token").Send(context.Background(), roomID, testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+
+	wantPath := "/_matrix/client/v3/rooms/" + roomID + "/send/m.room.message/rr-6f1d3c1e-8f1a-4a57-9a43-0d6f0b1b2c3d"
+	if method != http.MethodPut || path != wantPath {
+		t.Errorf("request = %s %s, want PUT %s", method, path, wantPath)
+	}
+	if auth != "Bearer secret-token" {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	if got.MsgType != "m.notice" || got.Format != "org.matrix.custom.html" {
+		t.Errorf("msgtype %q format %q, want an HTML m.notice", got.MsgType, got.Format)
+	}
+	wantBody := "New release: golang/go v1.2.0\nGo 1.2\n\nFaster <builds>\nSmaller binaries\nhttps://github.com/golang/go/releases/tag/v1.2.0"
+	if got.Body != wantBody {
+		t.Errorf("body = %q, want %q", got.Body, wantBody)
+	}
+	for _, want := range []string{
+		"<strong>New release: golang/go v1.2.0</strong>",
+		"<blockquote>Faster &lt;builds&gt;<br>Smaller binaries</blockquote>",
+		`<a href="https://github.com/golang/go/releases/tag/v1.2.0">View release</a>`,
+	} {
+		if !strings.Contains(got.FormattedBody, want) {
+			t.Errorf("formatted_body %q does not contain %q", got.FormattedBody, want)
+		}
+	}
+}
+
+func TestSendRetriesAfterLimitExceeded(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":100}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"event_id":"$1"}`)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	if _, err := NewNotifier(server.Client(), server.URL, "token").Send(context.Background(), roomID, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("retried after %s, want at least retry_after_ms", elapsed)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":120000}`)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client(), server.URL, "token").Send(context.Background(), roomID, testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != 2*time.Minute {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 2m", err)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusBadGateway, Body: "bad gateway"},
+		{Name: "revoked token", Status: http.StatusUnauthorized, Body: `{"errcode":"M_UNKNOWN_TOKEN","error":"Invalid access token"}`, Permanent: true},
+		{Name: "not in room", Status: http.StatusForbidden, Body: `{"errcode":"M_FORBIDDEN","error":"User not in room"}`, Permanent: true, Gone: true},
+		{Name: "no such room", Status: http.StatusNotFound, Body: `{"errcode":"M_NOT_FOUND","error":"Unknown room"}`, Permanent: true, Gone: true},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		_, err := NewNotifier(http.DefaultClient, serverURL, "token").Send(context.Background(), roomID, testMessage())
+		return err
+	})
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+// DigestHeadline is the title of a digest of n notifications.
+func DigestHeadline(n int) string {
+	if n == 1 {
+		return "Release digest: 1 update"
+	}
+	return fmt.Sprintf("Release digest: %d updates", n)
+}
+
+// RenderDigest renders the Text of a digest message: one line per entry, in Telegram HTML for
+// Telegram channels and plain text otherwise. The Telegram text includes the headline, which
+// other channels show as their title.
+func RenderDigest(channelType domain.ChannelType, entries []*Message) string {
+	var b strings.Builder
+	if channelType == domain.ChannelTelegram {
+		fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(DigestHeadline(len(entries))))
+	}
+
+	for _, entry := range entries {
+		s := Summarize(entry)
+		title := ""
+		if entry.Release != nil && s.Title != entry.Release.Tag {
+			title = " — " + s.Title
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if channelType != domain.ChannelTelegram {
+			b.WriteString("• " + s.Headline + title)
+			if s.Link != "" {
+				b.WriteString("\n  " + s.Link)
+			}
+			continue
+		}
+		if s.Link != "" {
+			fmt.Fprintf(&b, `• <a href="%s">%s</a>%s`, html.EscapeString(s.Link), html.EscapeString(s.Headline), html.EscapeString(title))
+		} else {
+			b.WriteString("• " + html.EscapeString(s.Headline+title))
+		}
+	}
+	return b.String()
+}
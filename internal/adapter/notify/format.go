@@ -0,0 +1,20 @@
+package notify
+
+import "fmt"
+
+// PublishedLayout is how publish dates are shown in channels without locale-aware rendering.
+const PublishedLayout = "2006-01-02 15:04 UTC"
+
+// FormatSize renders a byte count in human-readable binary units.
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxRetryAfter bounds how long a send waits out a rate limit. Longer waits are returned to the
+// notifier as a *RetryAfterError rather than holding up a sender.
+const MaxRetryAfter = time.Minute
+
+// RetryAfter reads how long a rate-limited webhook asks us to wait, from either a delay in
+// seconds or an HTTP date. It returns fallback when the header is missing or malformed.
+func RetryAfter(header http.Header, fallback time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return fallback
+}
+
+// Sleep waits for d or until ctx is done, whichever comes first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Excerpt shortens text to at most limit runes, preferring to cut at a line break.
+func Excerpt(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := runes[:limit-1]
+	for i := len(cut) - 1; i > limit/2; i-- {
+		if cut[i] == '\n' {
+			cut = cut[:i]
+			break
+		}
+	}
+	return string(cut) + "…"
+}
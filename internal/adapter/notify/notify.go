@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+var (
+	ErrInvalidChannel     = errors.New("invalid channel")
+	ErrUnsupportedChannel = errors.New("unsupported channel type")
+
+	// ErrPermanent is wrapped by Send errors that retrying cannot fix, such as a malformed
+	// message or a revoked bot token.
+	ErrPermanent = errors.New("permanent delivery failure")
+
+	// ErrGone is wrapped by Send errors meaning the destination no longer accepts messages at
+	// all, such as a Telegram chat that blocked the bot, a deleted webhook or a room the bot was
+	// removed from. Subscriptions to it are disabled.
+	ErrGone = fmt.Errorf("%w: destination gone", ErrPermanent)
+)
+
+// MovedError is returned by Send when the destination has permanently moved to Address, as
+// when a Telegram group is upgraded to a supergroup. The message was not delivered.
+type MovedError struct {
+	Address string
+}
+
+func (e *MovedError) Error() string {
+	return fmt.Sprintf("destination moved to %s", e.Address)
+}
+
+func (e *MovedError) Unwrap() error {
+	return ErrPermanent
+}
+
+// RetryAfterError is returned by Send when the destination rate-limited the message for longer
+// than a send waits out. The delivery is retried no sooner than After from now.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// Message is a notification handed to a Notifier. Text is the rendered template of the
+// subscription: the whole message for Telegram, and the body section for notifiers with rich
+// formatting, which build the rest of their layout from the structured fields.
+type Message struct {
+	Kind     string                  // Delivery kind, e.g. "release" or "release_updated"
+	Text     string                  // Rendered template; HTML subset supported by Telegram for Telegram channels
+	Repo     *domain.Repo            // Repo the delivery is about
+	Release  *domain.Release         // nil for repo-level notices
+	Previous *domain.Release         // Release published before Release, if any
+	Revision *domain.ReleaseRevision // Set for "release_updated" deliveries
+	Assets   []domain.ReleaseAsset   // Only loaded when the subscription lists assets
+
+	Subscription *domain.Subscription // nil if the subscription was removed after enqueueing
+	Delivery     *domain.Delivery     // Delivery being sent; receivers can dedupe on its ID
+
+	// Digest holds the notifications grouped by a "digest" message, which has no Repo,
+	// Release or Subscription of its own. Delivery is the first entry's.
+	Digest []*Message
+}
+
+// Result describes how a destination answered a Send, whether or not it succeeded.
+type Result struct {
+	StatusCode int // HTTP status of the last attempt; 0 for non-HTTP channels or transport errors
+}
+
+// Notifier delivers messages to one channel type. Addresses are the part of the
+// channel after the "<type>:" prefix.
+type Notifier interface {
+	// Validate reports whether address is a well-formed destination for this channel type.
+	Validate(address string) error
+	Send(ctx context.Context, address string, msg *Message) (Result, error)
+}
+
+// Registry routes deliveries to the Notifier registered for their channel type, keeping to
+// the rate limits set for it. It is safe for concurrent sends once set up.
+type Registry struct {
+	notifiers map[domain.ChannelType]Notifier
+	limiters  map[domain.ChannelType]*channelLimiter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		notifiers: make(map[domain.ChannelType]Notifier),
+		limiters:  make(map[domain.ChannelType]*channelLimiter),
+	}
+}
+
+func (r *Registry) Register(channelType domain.ChannelType, notifier Notifier) {
+	r.notifiers[channelType] = notifier
+}
+
+// Validate parses channel and checks its address with the matching Notifier.
+func (r *Registry) Validate(channel string) (domain.ChannelTarget, error) {
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return domain.ChannelTarget{}, fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+
+	notifier, ok := r.notifiers[target.Type]
+	if !ok {
+		return domain.ChannelTarget{}, fmt.Errorf("%w: %q", ErrUnsupportedChannel, target.Type)
+	}
+	if err := notifier.Validate(target.Address); err != nil {
+		return domain.ChannelTarget{}, fmt.Errorf("%w: %s: %v", ErrInvalidChannel, target.Type, err)
+	}
+	return target, nil
+}
+
+// Send delivers msg to the channel of a delivery, waiting for the channel's rate limits first.
+func (r *Registry) Send(ctx context.Context, delivery *domain.Delivery, msg *Message) (Result, error) {
+	target, err := domain.ParseChannel(delivery.Channel)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+
+	notifier, ok := r.notifiers[target.Type]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %q", ErrUnsupportedChannel, target.Type)
+	}
+	if err := r.wait(ctx, target); err != nil {
+		return Result{}, err
+	}
+	return notifier.Send(ctx, target.Address, msg)
+}
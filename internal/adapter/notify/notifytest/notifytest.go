@@ -0,0 +1,71 @@
+// Package notifytest holds the fixtures and assertions shared by the tests of the HTTP
+// notification adapters.
+package notifytest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// Main sets up the logger the adapters write to and runs the tests. Call it from TestMain.
+func Main(m *testing.M) {
+	logger.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+// Message returns a notification of the golang/go v1.2.0 release with the given notes.
+func Message(body string) *notify.Message {
+	return &notify.Message{
+		Kind: domain.DeliveryKindRelease,
+		Repo: &domain.Repo{Owner: "golang", Name: "go"},
+		Release: &domain.Release{
+			Tag:   "v1.2.0",
+			Title: "Go 1.2",
+			URL:   "https://github.com/golang/go/releases/tag/v1.2.0",
+			Body:  body,
+		},
+	}
+}
+
+// ErrorCase is a failed response from a service and how Send must classify it.
+type ErrorCase struct {
+	Name      string
+	Status    int
+	Body      string
+	Permanent bool // Send's error wraps notify.ErrPermanent
+	Gone      bool // Send's error wraps notify.ErrGone
+}
+
+// RunErrorCases serves each case in turn and checks that send, given the server's URL, fails
+// with an error classified as the case says.
+func RunErrorCases(t *testing.T, cases []ErrorCase, send func(t *testing.T, serverURL string, tc ErrorCase) error) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.Status)
+				_, _ = io.WriteString(w, tc.Body)
+			}))
+			defer server.Close()
+
+			err := send(t, server.URL, tc)
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if got := errors.Is(err, notify.ErrPermanent); got != tc.Permanent {
+				t.Errorf("errors.Is(%v, ErrPermanent) = %t, want %t", err, got, tc.Permanent)
+			}
+			if got := errors.Is(err, notify.ErrGone); got != tc.Gone {
+				t.Errorf("errors.Is(%v, ErrGone) = %t, want %t", err, got, tc.Gone)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// resolveTimeout bounds the DNS lookup of CheckPublicHost.
+const resolveTimeout = 5 * time.Second
+
+// ErrPrivateAddress is returned for user-supplied URLs pointing at loopback, private, link-local
+// or otherwise internal addresses, which would let subscribers reach our own network.
+var ErrPrivateAddress = fmt.Errorf("%w: destination is not a public address", ErrPermanent)
+
+// LookupIPFunc resolves a host name, like net.Resolver's LookupIPAddr.
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+// blockedNetworks are internal ranges that net.IP's predicates do not cover.
+var blockedNetworks = parseCIDRs(
+	"0.0.0.0/8",     // "This network"
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved, and broadcast
+	"64:ff9b::/96",  // NAT64, which can reach any IPv4 address
+)
+
+// NewPublicClient returns an HTTP client for channels whose URLs are supplied by subscribers. It
+// refuses to connect to internal addresses. The check is made on the address actually dialed,
+// which covers redirects and hosts that resolve differently at send time than when they were
+// validated. Proxies from the environment are not used, as they would hide the destination.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkDialAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// CheckPublicHost returns ErrPrivateAddress unless host is, or only resolves to, public addresses.
+// Notifiers call it from Validate, so that subscribing to an internal URL fails up front rather
+// than every send failing on NewPublicClient's check.
+func CheckPublicHost(host string, lookupIP LookupIPFunc) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := lookupIP(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve host %q: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// checkDialAddress is a net.Dialer Control function rejecting connections to internal addresses.
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// publicIP reports whether ip is a globally routable unicast address.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
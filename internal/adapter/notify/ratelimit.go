@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RateLimits are the send rates, in messages per second, allowed for a channel type: across
+// all destinations and per destination (chat, webhook, ...). Zero means unlimited.
+type RateLimits struct {
+	Global         float64
+	PerDestination float64
+}
+
+// DefaultRateLimits follow the limits documented by each service: Telegram allows about 30
+// messages per second per bot and one per second per chat, Slack one per second per incoming
+// webhook, Discord 30 per minute per webhook and Teams 4 per second per connector.
+var DefaultRateLimits = map[domain.ChannelType]RateLimits{
+	domain.ChannelTelegram: {Global: 30, PerDestination: 1},
+	domain.ChannelSlack:    {PerDestination: 1},
+	domain.ChannelDiscord:  {PerDestination: 0.5},
+	domain.ChannelTeams:    {PerDestination: 4},
+	domain.ChannelEmail:    {},
+	domain.ChannelWebhook:  {},
+	domain.ChannelMatrix:   {},
+	domain.ChannelNtfy:     {},
+	domain.ChannelGotify:   {},
+}
+
+var (
+	rateLimitConfig = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "releaseradar_rate_limit_per_second",
+		Help: "Configured send rate limits by channel type and scope (global or destination); 0 is unlimited.",
+	}, []string{"channel_type", "scope"})
+	rateLimitWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "releaseradar_rate_limit_wait_seconds",
+		Help:    "Time sends spent waiting for a rate limit token, by channel type and scope.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+	}, []string{"channel_type", "scope"})
+)
+
+// channelLimiter holds the token buckets of one channel type.
+type channelLimiter struct {
+	global         *ratelimit.Bucket
+	perDestination *ratelimit.Keyed
+}
+
+// SetRateLimits limits how fast messages are sent to channels of a type. Call it before
+// sending starts.
+func (r *Registry) SetRateLimits(channelType domain.ChannelType, limits RateLimits) {
+	r.limiters[channelType] = &channelLimiter{
+		global:         ratelimit.NewBucket(ratelimit.PerSecond(limits.Global)),
+		perDestination: ratelimit.NewKeyed(ratelimit.PerSecond(limits.PerDestination)),
+	}
+	rateLimitConfig.WithLabelValues(string(channelType), "global").Set(limits.Global)
+	rateLimitConfig.WithLabelValues(string(channelType), "destination").Set(limits.PerDestination)
+}
+
+// wait blocks until target may be sent another message. The destination's own limit is
+// waited for first, so that a busy destination does not hold global tokens it cannot use.
+func (r *Registry) wait(ctx context.Context, target domain.ChannelTarget) error {
+	limiter, ok := r.limiters[target.Type]
+	if !ok {
+		return nil
+	}
+
+	waited, err := limiter.perDestination.Wait(ctx, destination(target))
+	if err != nil {
+		return err
+	}
+	if waited > 0 {
+		rateLimitWait.WithLabelValues(string(target.Type), "destination").Observe(waited.Seconds())
+	}
+
+	waited, err = limiter.global.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if waited > 0 {
+		rateLimitWait.WithLabelValues(string(target.Type), "global").Observe(waited.Seconds())
+	}
+	return nil
+}
+
+// destination is the key of a target's per-destination limit. Topics of a Telegram forum
+// ("<chat>/<thread>") share the limit of their chat.
+func destination(target domain.ChannelTarget) string {
+	if target.Type == domain.ChannelTelegram {
+		chat, _, _ := strings.Cut(target.Address, "/")
+		return chat
+	}
+	return target.Address
+}
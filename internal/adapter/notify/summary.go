@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+// Summary is the channel-neutral gist of a Message, for adapters that lay out their own
+// title, body and link rather than use Message.Text.
+type Summary struct {
+	RepoName string // "owner/name"
+	RepoURL  string
+	Headline string // e.g. "New release: owner/name v1.2.0"
+	Title    string // Release title, falling back to the tag; empty for repo-level notices
+	Details  string // Rendered body template, or release notes / change summary; untruncated, may be empty
+	Link     string // Where "View release" points; empty when there is nothing to open
+
+	Type      domain.ReleaseType // Empty for repo-level notices
+	Published time.Time          // Zero when unknown
+	Author    string             // GitHub login of the publisher, if known
+	AuthorURL string
+}
+
+// Summarize extracts the Summary of msg.
+func Summarize(msg *Message) Summary {
+	if msg.Kind == domain.DeliveryKindDigest {
+		return Summary{Headline: DigestHeadline(len(msg.Digest)), Details: msg.Text}
+	}
+
+	s := Summary{RepoName: "unknown repository"}
+	if msg.Repo != nil {
+		s.RepoName = msg.Repo.Owner + "/" + msg.Repo.Name
+		s.RepoURL = "https://github.com/" + s.RepoName
+	}
+
+	release := msg.Release
+	if release == nil {
+		s.Headline = fmt.Sprintf("Stopped tracking %s: the repository no longer exists on GitHub", s.RepoName)
+		s.Details = msg.Text
+		return s
+	}
+
+	s.Title = release.Title
+	if s.Title == "" {
+		s.Title = release.Tag
+	}
+	s.Link = release.URL
+	s.Type = release.Type()
+	s.Published = release.PublishedAt
+	if release.Author != "" {
+		s.Author = release.Author
+		s.AuthorURL = "https://github.com/" + release.Author
+	}
+
+	switch msg.Kind {
+	case domain.DeliveryKindReleaseUpdated:
+		s.Headline = fmt.Sprintf("Release notes updated: %s %s", s.RepoName, release.Tag)
+		if msg.Revision != nil {
+			s.Details = msg.Revision.Summary
+		}
+	case domain.DeliveryKindReleaseDeleted:
+		s.Headline = fmt.Sprintf("Release retracted: %s %s", s.RepoName, release.Tag)
+		s.Details = "This release is no longer published on GitHub."
+		s.Link = ""
+	default:
+		s.Headline = fmt.Sprintf("New release: %s %s", s.RepoName, release.Tag)
+		s.Details = release.Body
+	}
+	if msg.Text != "" {
+		s.Details = msg.Text
+	}
+	return s
+}
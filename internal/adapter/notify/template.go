@@ -0,0 +1,264 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/markdown"
+)
+
+const (
+	// MaxTemplateLength bounds user templates stored on subscriptions.
+	MaxTemplateLength = 4000
+	// maxRenderedLength stops runaway templates (e.g. {{range 1000000000}}) early.
+	maxRenderedLength = 16 << 10
+	excerptLength     = 500
+)
+
+var (
+	ErrInvalidTemplate = errors.New("invalid template")
+	errOutputTooLong   = errors.New("rendered message is too long")
+)
+
+// TemplateData is what message templates are executed with. Field names are part of the API
+// and documented in openapi.yaml; add fields, never rename them.
+type TemplateData struct {
+	Kind         string                // release, release_updated, release_deleted or repo_deactivated
+	Repo         TemplateRepo          //
+	Release      *TemplateRelease      // nil for repo_deactivated
+	Subscription *TemplateSubscription // nil when rendering for a removed subscription
+	Excerpt      string                // First 500 characters of the release notes
+	Changes      string                // Summary of edited release notes, for release_updated
+	Previous     string                // Tag of the release published before this one, if any
+	SemverDiff   string                // Version component bumped since Previous: major, minor, patch, prerelease or unknown
+	Assets       []domain.ReleaseAsset // Only set when the subscription lists assets
+}
+
+type TemplateRepo struct {
+	Owner    string
+	Name     string
+	FullName string // "owner/name"
+	URL      string
+}
+
+type TemplateRelease struct {
+	Tag        string
+	Title      string // Falls back to the tag
+	URL        string
+	Body       string // Full release notes
+	Type       string // major, minor, patch, prerelease or unknown
+	Prerelease bool
+	Published  time.Time
+	Author     string
+	AuthorURL  string
+}
+
+type TemplateSubscription struct {
+	Channel        string
+	NotifyOnUpdate bool
+	NotifyOnDelete bool
+	IncludeAssets  bool
+}
+
+// Functions available to templates in addition to the text/template builtins. "markdown" is
+// added per template flavour.
+var templateFuncs = map[string]any{
+	"truncate": func(limit int, s string) string {
+		if limit < 2 {
+			return ""
+		}
+		return Excerpt(s, limit)
+	},
+	"size": FormatSize,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(PublishedLayout)
+	},
+}
+
+// markdownHTML converts release notes to Telegram HTML of about limit visible characters,
+// ending with a "read more" link to readMoreURL when cut.
+func markdownHTML(limit int, readMoreURL, md string) htmltemplate.HTML {
+	return htmltemplate.HTML(markdown.Parse(md).HTML(limit, readMoreURL))
+}
+
+// markdownText is markdownHTML for plain-text channels; the link is not needed there.
+func markdownText(limit int, _ string, md string) string {
+	return markdown.Parse(md).Text(limit)
+}
+
+// defaultTelegramTemplate renders the whole Telegram message in its HTML subset.
+const defaultTelegramTemplate = `
+{{- if not .Release -}}
+Stopped tracking <b><a href="{{.Repo.URL}}">{{.Repo.FullName}}</a></b>: the repository no longer exists on GitHub
+{{- else if eq .Kind "release_deleted" -}}
+Release retracted from <a href="{{.Repo.URL}}">{{.Repo.FullName}}</a>: <b>{{.Release.Title}}</b> (<code>{{.Release.Tag}}</code>) is no longer published
+{{- else -}}
+{{if eq .Kind "release_updated"}}Updated release notes for{{else}}New release for{{end}} <a href="{{.Repo.URL}}">{{.Repo.FullName}}</a>: <b>{{.Release.Title}}</b> (<code>{{.Release.Tag}}</code>)
+{{if ne .Release.Type "unknown"}}<i>{{.Release.Type}}</i> · {{end}}{{date .Release.Published}}{{with .Release.Author}} · by <a href="{{$.Release.AuthorURL}}">@{{.}}</a>{{end}}
+{{- if eq .Kind "release"}}{{with .Release.Body}}
+
+{{markdown 1500 $.Release.URL .}}
+{{end}}{{end}}
+{{- with .Changes}}
+{{.}}{{end}}
+{{- with .Release.URL}}
+{{.}}{{end}}
+{{- if and (eq .Kind "release") .Assets}}
+
+Assets:{{range .Assets}}
+• <a href="{{.DownloadURL}}">{{.Name}}</a> ({{size .Size}}){{with .SHA256}} sha256:<code>{{.}}</code>{{end}}{{end}}
+{{- end}}
+{{- end}}`
+
+// defaultBodyTemplate renders the body section of channels that lay out title, fields and
+// links themselves.
+const defaultBodyTemplate = `
+{{- if eq .Kind "release_updated"}}{{.Changes}}
+{{- else if eq .Kind "release_deleted"}}This release is no longer published on GitHub.
+{{- else if .Release}}{{.Release.Body}}
+{{- end}}`
+
+// DefaultTemplate returns the template used for a channel type when the subscription has none.
+func DefaultTemplate(channelType domain.ChannelType) string {
+	if channelType == domain.ChannelTelegram {
+		return defaultTelegramTemplate
+	}
+	return defaultBodyTemplate
+}
+
+// Template is a parsed message template. For Telegram it renders the whole message as HTML,
+// with values escaped automatically; for other channels it renders the plain-text body.
+type Template struct {
+	exec interface {
+		Execute(w io.Writer, data any) error
+	}
+}
+
+// ParseTemplate parses a template for a channel type and checks that it renders for every
+// delivery kind, so that mistakes surface when the template is saved rather than at delivery.
+func ParseTemplate(channelType domain.ChannelType, text string) (*Template, error) {
+	if len(text) > MaxTemplateLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidTemplate, MaxTemplateLength)
+	}
+
+	t := &Template{}
+	var err error
+	if channelType == domain.ChannelTelegram {
+		t.exec, err = htmltemplate.New("message").Funcs(templateFuncs).Funcs(map[string]any{"markdown": markdownHTML}).Option("missingkey=error").Parse(text)
+	} else {
+		t.exec, err = template.New("message").Funcs(templateFuncs).Funcs(map[string]any{"markdown": markdownText}).Option("missingkey=error").Parse(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	for _, data := range sampleData() {
+		if _, err := t.execute(data); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, data.Kind, err)
+		}
+	}
+	return t, nil
+}
+
+// Render executes the template for msg.
+func (t *Template) Render(msg *Message) (string, error) {
+	return t.execute(NewTemplateData(msg))
+}
+
+func (t *Template) execute(data *TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.exec.Execute(&limitedWriter{buf: &buf, limit: maxRenderedLength}, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// NewTemplateData flattens a Message into the documented template data model.
+func NewTemplateData(msg *Message) *TemplateData {
+	s := Summarize(msg)
+	data := &TemplateData{Kind: msg.Kind, Assets: msg.Assets}
+	if msg.Repo != nil {
+		data.Repo = TemplateRepo{Owner: msg.Repo.Owner, Name: msg.Repo.Name, FullName: s.RepoName, URL: s.RepoURL}
+	} else {
+		data.Repo = TemplateRepo{FullName: s.RepoName}
+	}
+
+	if release := msg.Release; release != nil {
+		data.Release = &TemplateRelease{
+			Tag:        release.Tag,
+			Title:      s.Title,
+			URL:        release.URL,
+			Body:       release.Body,
+			Type:       string(s.Type),
+			Prerelease: release.Prerelease,
+			Published:  release.PublishedAt,
+			Author:     s.Author,
+			AuthorURL:  s.AuthorURL,
+		}
+		data.Excerpt = Excerpt(strings.TrimSpace(release.Body), excerptLength)
+		data.SemverDiff = string(domain.ReleaseTypeUnknown)
+		if msg.Previous != nil {
+			data.Previous = msg.Previous.Tag
+			data.SemverDiff = string(domain.SemverDiff(msg.Previous.Tag, release.Tag))
+		}
+	}
+	if msg.Revision != nil {
+		data.Changes = msg.Revision.Summary
+	}
+	if sub := msg.Subscription; sub != nil {
+		data.Subscription = &TemplateSubscription{
+			Channel:        sub.Channel,
+			NotifyOnUpdate: sub.NotifyOnUpdate,
+			NotifyOnDelete: sub.NotifyOnDelete,
+			IncludeAssets:  sub.IncludeAssets,
+		}
+	}
+	return data
+}
+
+// sampleData covers every delivery kind, for validating templates before they are stored.
+func sampleData() []*TemplateData {
+	repo := &domain.Repo{Owner: "octocat", Name: "hello-world"}
+	previous := &domain.Release{Tag: "v1.1.0"}
+	release := &domain.Release{
+		Tag:         "v1.2.0",
+		Title:       "Hello World 1.2",
+		URL:         "https://github.com/octocat/hello-world/releases/tag/v1.2.0",
+		Body:        "## Changes\n- Added a greeting",
+		Author:      "octocat",
+		PublishedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	sub := &domain.Subscription{Channel: "telegram:123456789", IncludeAssets: true}
+	assets := []domain.ReleaseAsset{{Name: "hello_1.2.0_linux_amd64.tar.gz", Size: 1 << 20, DownloadURL: release.URL}}
+	revision := &domain.ReleaseRevision{Summary: "+1 line(s), -0 line(s)"}
+
+	return []*TemplateData{
+		NewTemplateData(&Message{Kind: domain.DeliveryKindRelease, Repo: repo, Release: release, Previous: previous, Subscription: sub, Assets: assets}),
+		NewTemplateData(&Message{Kind: domain.DeliveryKindReleaseUpdated, Repo: repo, Release: release, Previous: previous, Revision: revision, Subscription: sub}),
+		NewTemplateData(&Message{Kind: domain.DeliveryKindReleaseDeleted, Repo: repo, Release: release, Subscription: sub}),
+		NewTemplateData(&Message{Kind: domain.DeliveryKindRepoDeactivated, Repo: repo}),
+	}
+}
+
+// limitedWriter fails writes past limit, which aborts template execution.
+type limitedWriter struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errOutputTooLong
+	}
+	return w.buf.Write(p)
+}
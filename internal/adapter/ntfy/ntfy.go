@@ -0,0 +1,177 @@
+package ntfy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 10 * time.Second
+	// ntfy turns larger messages into attachments
+	maxMessageBytes = 4096
+)
+
+var topicPattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+type notifier struct {
+	httpClient *http.Client
+	lookupIP   notify.LookupIPFunc
+}
+
+// NewNotifier returns a notify.Notifier for "ntfy:<topic URL>" channels such as
+// "ntfy:https://ntfy.sh/my-releases". Protected topics take ntfy's "?auth=" query parameter.
+// Servers must be publicly reachable; httpClient should come from notify.NewPublicClient.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient, lookupIP: net.DefaultResolver.LookupIPAddr}
+}
+
+func (n *notifier) Validate(topicURL string) error {
+	if _, _, err := splitTopicURL(topicURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(topicURL)
+	// Sending re-checks the address it connects to; this catches the obvious cases up front
+	return notify.CheckPublicHost(u.Hostname(), n.lookupIP)
+}
+
+// splitTopicURL returns the server's publish endpoint (keeping any auth query) and the topic name.
+func splitTopicURL(topicURL string) (string, string, error) {
+	u, err := url.Parse(topicURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid topic URL: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", fmt.Errorf("%q is not an ntfy topic URL", topicURL)
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	topic := path[slash+1:]
+	if !topicPattern.MatchString(topic) {
+		return "", "", fmt.Errorf("%q does not name an ntfy topic", topicURL)
+	}
+
+	u.Path = path[:slash+1]
+	return u.String(), topic, nil
+}
+
+// publishRequest is ntfy's JSON publishing format, which unlike the header-based one carries
+// non-ASCII titles reliably.
+type publishRequest struct {
+	Topic   string   `json:"topic"`
+	Title   string   `json:"title"`
+	Message string   `json:"message"`
+	Tags    []string `json:"tags,omitempty"`
+	Click   string   `json:"click,omitempty"`
+	Actions []action `json:"actions,omitempty"`
+}
+
+type action struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+func (n *notifier) Send(ctx context.Context, topicURL string, msg *notify.Message) (notify.Result, error) {
+	endpoint, topic, err := splitTopicURL(topicURL)
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("%w: %v", notify.ErrPermanent, err)
+	}
+
+	payload, err := json.Marshal(buildRequest(topic, msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode ntfy message: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, err := n.post(ctx, endpoint, payload)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("ntfy server rate limited, retrying in %s", retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+func buildRequest(topic string, msg *notify.Message) publishRequest {
+	summary := notify.Summarize(msg)
+
+	title := summary.Headline
+	message := summary.Title
+	if details := strings.TrimSpace(summary.Details); details != "" {
+		if message != "" {
+			message += "\n\n"
+		}
+		message += details
+	}
+	if message == "" {
+		message = summary.Headline
+	}
+	// Excerpt counts runes; halve the budget to stay under the byte limit for non-ASCII notes
+	message = notify.Excerpt(message, maxMessageBytes/2)
+
+	req := publishRequest{Topic: topic, Title: title, Message: message, Tags: []string{"package"}}
+	if msg.Release == nil && msg.Kind != domain.DeliveryKindDigest {
+		req.Tags = []string{"warning"}
+	}
+	if summary.Link != "" {
+		req.Click = summary.Link
+		req.Actions = []action{{Action: "view", Label: "View release", URL: summary.Link}}
+	}
+	return req
+}
+
+// post publishes one message. A non-zero duration means the server rate-limited the request
+// and the message should be retried after it.
+func (n *notifier) post(ctx context.Context, endpoint string, payload []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create ntfy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("ntfy client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return result, notify.RetryAfter(resp.Header, defaultRetryAfter), fmt.Errorf("ntfy client error: rate limited")
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// Topic is reserved or the auth token was revoked
+		return result, 0, fmt.Errorf("ntfy client error: %w: status %d: %s", notify.ErrGone, resp.StatusCode, strings.TrimSpace(string(body)))
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// Not an ntfy server, or a message it refuses, e.g. for being too large
+		return result, 0, fmt.Errorf("ntfy client error: %w: status %d: %s", notify.ErrPermanent, resp.StatusCode, strings.TrimSpace(string(body)))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return result, 0, fmt.Errorf("ntfy client error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return result, 0, nil
+}
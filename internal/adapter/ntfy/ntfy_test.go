@@ -0,0 +1,126 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+)
+
+func TestMain(m *testing.M) {
+	notifytest.Main(m)
+}
+
+func testMessage() *notify.Message {
+	return notifytest.Message("Faster builds")
+}
+
+func TestSendPayload(t *testing.T) {
+	var path, query string
+	var got publishRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, `{"id":"1"}`)
+	}))
+	defer server.Close()
+
+	result, err := NewNotifier(server.Client()).Send(context.Background(), server.URL+"/releases?auth=abc", testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+	if path != "/" || query != "auth=abc" {
+		t.Errorf("published to %s?%s, want the server root with the auth query", path, query)
+	}
+
+	want := publishRequest{
+		Topic:   "releases",
+		Title:   "New release: golang/go v1.2.0",
+		Message: "Go 1.2\n\nFaster builds",
+		Tags:    []string{"package"},
+		Click:   "https://github.com/golang/go/releases/tag/v1.2.0",
+		Actions: []action{{Action: "view", Label: "View release", URL: "https://github.com/golang/go/releases/tag/v1.2.0"}},
+	}
+	if got.Topic != want.Topic || got.Title != want.Title || got.Message != want.Message || got.Click != want.Click {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "package" {
+		t.Errorf("tags = %v, want [package]", got.Tags)
+	}
+	if len(got.Actions) != 1 || got.Actions[0] != want.Actions[0] {
+		t.Errorf("actions = %+v, want %+v", got.Actions, want.Actions)
+	}
+}
+
+func TestSendRetriesAfter429(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, `{"id":"1"}`)
+	}))
+	defer server.Close()
+
+	if _, err := NewNotifier(server.Client()).Send(context.Background(), server.URL+"/releases", testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "90")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL+"/releases", testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != 90*time.Second {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 90s", err)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	const body = `{"code":40301,"http":403,"error":"forbidden"}`
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusInternalServerError, Body: body},
+		{Name: "too large", Status: http.StatusRequestEntityTooLarge, Body: body, Permanent: true},
+		{Name: "not found", Status: http.StatusNotFound, Body: body, Permanent: true},
+		{Name: "revoked token", Status: http.StatusUnauthorized, Body: body, Permanent: true, Gone: true},
+		{Name: "reserved topic", Status: http.StatusForbidden, Body: body, Permanent: true, Gone: true},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		_, err := NewNotifier(http.DefaultClient).Send(context.Background(), serverURL+"/releases", testMessage())
+		return err
+	})
+}
+
+func TestValidateRejectsInternalServers(t *testing.T) {
+	n := NewNotifier(http.DefaultClient)
+	for _, u := range []string{"http://127.0.0.1/releases", "http://192.168.1.5:8080/releases", "http://[::1]/releases"} {
+		if err := n.Validate(u); !errors.Is(err, notify.ErrPrivateAddress) {
+			t.Errorf("Validate(%q) = %v, want ErrPrivateAddress", u, err)
+		}
+	}
+	if err := n.Validate("https://203.0.113.10/releases"); err != nil {
+		t.Errorf("Validate of a public server: %v", err)
+	}
+}
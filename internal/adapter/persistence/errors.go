@@ -3,3 +3,11 @@ package persistence
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+
+// ErrClaimLost is returned when recording the outcome of a delivery that another worker has
+// claimed since, e.g. because this worker's lease expired.
+var ErrClaimLost = errors.New("delivery claim lost")
+
+// ErrDuplicateDelivery is returned by CreateDelivery when the event was already delivered to
+// the same user and channel; nothing is inserted.
+var ErrDuplicateDelivery = errors.New("duplicate delivery")
@@ -11,6 +11,7 @@ import (
 	"github.com/mackb/releaseradar/internal/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -78,6 +79,23 @@ func (p *PostgresStore) GetUserByEmail(ctx context.Context, email string) (*doma
 	return &user, nil
 }
 
+func (p *PostgresStore) GetUserByTelegramChatID(ctx context.Context, chatID int64) (*domain.User, error) {
+	db := getDB(ctx, p)
+	var user domain.User
+	if err := db.WithContext(ctx).First(&user, "telegram_chat_id = ?", chatID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (p *PostgresStore) UpdateUser(ctx context.Context, user *domain.User) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Save(user).Error
+}
+
 // --- Repo Repository Implementations ---
 
 func (p *PostgresStore) CreateRepo(ctx context.Context, repo *domain.Repo) error {
@@ -123,6 +141,27 @@ func (p *PostgresStore) ListRepos(ctx context.Context, userID uuid.UUID) ([]doma
 	return repos, nil
 }
 
+func (p *PostgresStore) ListActiveRepos(ctx context.Context) ([]domain.Repo, error) {
+	db := getDB(ctx, p)
+	var repos []domain.Repo
+	if err := db.WithContext(ctx).Where("deactivated_at IS NULL").Order("last_checked_at").Find(&repos).Error; err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (p *PostgresStore) ListReposByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Repo, error) {
+	db := getDB(ctx, p)
+	var repos []domain.Repo
+	if len(ids) == 0 {
+		return repos, nil
+	}
+	if err := db.WithContext(ctx).Where("id IN ?", ids).Find(&repos).Error; err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
 // --- Subscription Repository Implementations ---
 
 func (p *PostgresStore) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
@@ -142,11 +181,33 @@ func (p *PostgresStore) GetSubscription(ctx context.Context, repoID, userID uuid
 	return &sub, nil
 }
 
+func (p *PostgresStore) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	db := getDB(ctx, p)
+	var sub domain.Subscription
+	if err := db.WithContext(ctx).First(&sub, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (p *PostgresStore) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Save(sub).Error
+}
+
 func (p *PostgresStore) DeleteSubscription(ctx context.Context, repoID, userID uuid.UUID, channel string) error {
 	db := getDB(ctx, p)
 	return db.WithContext(ctx).Where("repo_id = ? AND user_id = ? AND channel = ?", repoID, userID, channel).Delete(&domain.Subscription{}).Error
 }
 
+func (p *PostgresStore) DeleteSubscriptionByID(ctx context.Context, id uuid.UUID) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Where("id = ?", id).Delete(&domain.Subscription{}).Error
+}
+
 func (p *PostgresStore) ListSubscriptionsByRepoID(ctx context.Context, repoID uuid.UUID) ([]domain.Subscription, error) {
 	db := getDB(ctx, p)
 	var subs []domain.Subscription
@@ -205,27 +266,238 @@ func (p *PostgresStore) ListReleasesByRepoID(ctx context.Context, repoID uuid.UU
 	return releases, nil
 }
 
+// ListReleasesWithoutDeliveries returns the releases created since the given time that have no
+// "release" deliveries although their repo had active, unmuted subscriptions when they were created.
+func (p *PostgresStore) ListReleasesWithoutDeliveries(ctx context.Context, since time.Time) ([]domain.Release, error) {
+	db := getDB(ctx, p)
+	var releases []domain.Release
+	err := db.WithContext(ctx).
+		Where("created_at >= ? AND deleted_at IS NULL", since).
+		Where(`EXISTS (SELECT 1 FROM subscriptions s WHERE s.repo_id = releases.repo_id AND s.disabled_at IS NULL
+			AND s.created_at <= releases.created_at AND (s.muted_until IS NULL OR s.muted_until <= releases.created_at))`).
+		Where("NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.release_id = releases.id AND d.kind = ?)", domain.DeliveryKindRelease).
+		Order("created_at").
+		Find(&releases).Error
+	if err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// GetPreviousRelease returns the latest published release of a repo before the given time.
+func (p *PostgresStore) GetPreviousRelease(ctx context.Context, repoID uuid.UUID, before time.Time) (*domain.Release, error) {
+	db := getDB(ctx, p)
+	var release domain.Release
+	err := db.WithContext(ctx).
+		Where("repo_id = ? AND deleted_at IS NULL AND published_at < ?", repoID, before).
+		Order("published_at DESC").
+		First(&release).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &release, nil
+}
+
+func (p *PostgresStore) UpdateRelease(ctx context.Context, release *domain.Release) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Save(release).Error
+}
+
+func (p *PostgresStore) CreateReleaseRevision(ctx context.Context, revision *domain.ReleaseRevision) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Create(revision).Error
+}
+
+func (p *PostgresStore) GetReleaseRevisionByID(ctx context.Context, id uuid.UUID) (*domain.ReleaseRevision, error) {
+	db := getDB(ctx, p)
+	var revision domain.ReleaseRevision
+	if err := db.WithContext(ctx).First(&revision, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (p *PostgresStore) ListReleaseRevisions(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseRevision, error) {
+	db := getDB(ctx, p)
+	var revisions []domain.ReleaseRevision
+	if err := db.WithContext(ctx).Where("release_id = ?", releaseID).Order("created_at").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// ReplaceReleaseAssets swaps the stored asset list of a release for the given one.
+func (p *PostgresStore) ReplaceReleaseAssets(ctx context.Context, releaseID uuid.UUID, assets []domain.ReleaseAsset) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("release_id = ?", releaseID).Delete(&domain.ReleaseAsset{}).Error; err != nil {
+			return err
+		}
+		if len(assets) == 0 {
+			return nil
+		}
+		return tx.Create(&assets).Error
+	})
+}
+
+func (p *PostgresStore) ListReleaseAssets(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseAsset, error) {
+	db := getDB(ctx, p)
+	var assets []domain.ReleaseAsset
+	if err := db.WithContext(ctx).Where("release_id = ?", releaseID).Order("name").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
 // --- Delivery Repository Implementations ---
 
+// CreateDelivery inserts a delivery unless one for the same event exists (see the
+// deliveries_event_key index), in which case ErrDuplicateDelivery is returned.
 func (p *PostgresStore) CreateDelivery(ctx context.Context, delivery *domain.Delivery) error {
 	db := getDB(ctx, p)
-	return db.WithContext(ctx).Create(delivery).Error
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateDelivery
+	}
+	return nil
+}
+
+// UpdateDeliveryStatus records the outcome of a delivery and releases its claim. claimedBy is
+// the worker that claimed it, or empty for deliveries that are not claimed, such as digests; if
+// the delivery is claimed by anyone else by now, nothing is written and ErrClaimLost is returned.
+func (p *PostgresStore) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, claimedBy, status, lastError string, attempt int) error {
+	return p.updateClaimedDelivery(ctx, id, claimedBy, map[string]interface{}{"status": status, "last_error": lastError, "attempt": attempt})
+}
+
+// RecordDeliveryAttempt is UpdateDeliveryStatus for send attempts, also storing the
+// destination's response code and when a failed delivery is retried.
+func (p *PostgresStore) RecordDeliveryAttempt(ctx context.Context, id uuid.UUID, claimedBy, status, lastError string, attempt, responseCode int, nextAttemptAt *time.Time) error {
+	return p.updateClaimedDelivery(ctx, id, claimedBy, map[string]interface{}{"status": status, "last_error": lastError, "attempt": attempt, "response_code": responseCode, "next_attempt_at": nextAttemptAt})
+}
+
+// updateClaimedDelivery applies updates to a delivery still claimed by claimedBy and releases
+// the claim.
+func (p *PostgresStore) updateClaimedDelivery(ctx context.Context, id uuid.UUID, claimedBy string, updates map[string]interface{}) error {
+	db := getDB(ctx, p)
+	updates["claimed_by"] = ""
+	updates["claimed_until"] = nil
+	updates["updated_at"] = time.Now()
+	result := db.WithContext(ctx).Model(&domain.Delivery{}).Where("id = ? AND claimed_by = ?", id, claimedBy).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrClaimLost
+	}
+	return nil
 }
 
-func (p *PostgresStore) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status, lastError string, attempt int) error {
+// ClaimDeliveries claims up to batchSize deliveries waiting to be sent, oldest first, for
+// workerID until leaseDuration from now: pending ones, and failed ones whose retry is due.
+// Deliveries claimed by another worker are skipped unless its lease expired, and rows locked by
+// a concurrent claim are skipped rather than waited for, so workers never get the same
+// delivery. Recording the outcome of a delivery releases its claim.
+func (p *PostgresStore) ClaimDeliveries(ctx context.Context, workerID string, batchSize int, leaseDuration time.Duration) ([]domain.Delivery, error) {
+	return p.claimDeliveries(ctx, workerID, leaseDuration, "TRUE", nil, batchSize)
+}
+
+// ClaimDeliveriesByID is ClaimDeliveries for the given deliveries, e.g. ones announced on the
+// delivery stream. Those already sent, scheduled or claimed are left out.
+func (p *PostgresStore) ClaimDeliveriesByID(ctx context.Context, workerID string, ids []uuid.UUID, leaseDuration time.Duration) ([]domain.Delivery, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return p.claimDeliveries(ctx, workerID, leaseDuration, "id IN ?", ids, len(ids))
+}
+
+// claimDeliveries claims up to limit sendable deliveries that also match filter with arg.
+func (p *PostgresStore) claimDeliveries(ctx context.Context, workerID string, leaseDuration time.Duration, filter string, arg interface{}, limit int) ([]domain.Delivery, error) {
 	db := getDB(ctx, p)
-	return db.WithContext(ctx).Model(&domain.Delivery{}).Where("id = ?", id).Updates(map[string]interface{}{"status": status, "last_error": lastError, "attempt": attempt, "updated_at": time.Now()}).Error
+	now := time.Now()
+	args := []interface{}{workerID, now.Add(leaseDuration), now, now}
+	if arg != nil {
+		args = append(args, arg)
+	}
+	args = append(args, limit)
+
+	var deliveries []domain.Delivery
+	err := db.WithContext(ctx).Raw(`
+		UPDATE deliveries SET claimed_by = ?, claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM deliveries
+			WHERE (status = 'pending' OR (status = 'failed' AND next_attempt_at <= ?))
+				AND (claimed_until IS NULL OR claimed_until <= ?)
+				AND `+filter+`
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, args...).Scan(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
 }
 
-func (p *PostgresStore) ListPendingDeliveries(ctx context.Context) ([]domain.Delivery, error) {
+// ListDeliveriesByStatus returns up to limit deliveries with status, of one user if userID is
+// set, most recently updated first.
+func (p *PostgresStore) ListDeliveriesByStatus(ctx context.Context, status string, userID *uuid.UUID, limit int) ([]domain.Delivery, error) {
 	db := getDB(ctx, p)
+	query := db.WithContext(ctx).Where("status = ?", status)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
 	var deliveries []domain.Delivery
-	if err := db.WithContext(ctx).Where("status = ?", "pending").Find(&deliveries).Error; err != nil {
+	if err := query.Order("updated_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
 		return nil, err
 	}
 	return deliveries, nil
 }
 
+func (p *PostgresStore) GetDeliveryByID(ctx context.Context, id uuid.UUID) (*domain.Delivery, error) {
+	db := getDB(ctx, p)
+	var delivery domain.Delivery
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// RequeueDelivery makes a delivery pending again with a fresh set of attempts.
+func (p *PostgresStore) RequeueDelivery(ctx context.Context, id uuid.UUID) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Model(&domain.Delivery{}).Where("id = ?", id).Updates(map[string]interface{}{"status": "pending", "attempt": 0, "next_attempt_at": nil, "claimed_by": "", "claimed_until": nil, "updated_at": time.Now()}).Error
+}
+
+// ListDueDeliveries returns the deliveries with a scheduled status ("digest" or "deferred")
+// that are due by now, oldest first.
+func (p *PostgresStore) ListDueDeliveries(ctx context.Context, status string, now time.Time) ([]domain.Delivery, error) {
+	db := getDB(ctx, p)
+	var deliveries []domain.Delivery
+	if err := db.WithContext(ctx).Where("status = ? AND scheduled_at <= ?", status, now).Order("created_at").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ScheduleDeliveries moves deliveries to status, due at scheduledAt; nil clears the schedule.
+func (p *PostgresStore) ScheduleDeliveries(ctx context.Context, ids []uuid.UUID, status string, scheduledAt *time.Time) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Model(&domain.Delivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{"status": status, "scheduled_at": scheduledAt, "claimed_by": "", "claimed_until": nil, "updated_at": time.Now()}).Error
+}
+
 func (p *PostgresStore) GetDelivery(ctx context.Context, releaseID, userID uuid.UUID, channel string) (*domain.Delivery, error) {
 	db := getDB(ctx, p)
 	var delivery domain.Delivery
@@ -237,3 +509,68 @@ func (p *PostgresStore) GetDelivery(ctx context.Context, releaseID, userID uuid.
 	}
 	return &delivery, nil
 }
+
+// --- Telegram Topic Repository Implementations ---
+
+func (p *PostgresStore) GetTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) (*domain.TelegramTopic, error) {
+	db := getDB(ctx, p)
+	var topic domain.TelegramTopic
+	if err := db.WithContext(ctx).Where("chat_id = ? AND repo_id = ?", chatID, repoID).Take(&topic).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &topic, nil
+}
+
+// SaveTelegramTopic stores the topic of a repo in a chat, replacing any previous one.
+func (p *PostgresStore) SaveTelegramTopic(ctx context.Context, topic *domain.TelegramTopic) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "repo_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"thread_id", "created_at"}),
+	}).Create(topic).Error
+}
+
+func (p *PostgresStore) DeleteTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Where("chat_id = ? AND repo_id = ?", chatID, repoID).Delete(&domain.TelegramTopic{}).Error
+}
+
+// --- Quiet Hours Repository Implementations ---
+
+func (p *PostgresStore) GetQuietHours(ctx context.Context, userID uuid.UUID, channel string) (*domain.QuietHours, error) {
+	db := getDB(ctx, p)
+	var quiet domain.QuietHours
+	if err := db.WithContext(ctx).Where("user_id = ? AND channel = ?", userID, channel).Take(&quiet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quiet, nil
+}
+
+func (p *PostgresStore) ListQuietHours(ctx context.Context, userID uuid.UUID) ([]domain.QuietHours, error) {
+	db := getDB(ctx, p)
+	var quiet []domain.QuietHours
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).Order("channel").Find(&quiet).Error; err != nil {
+		return nil, err
+	}
+	return quiet, nil
+}
+
+// SaveQuietHours stores the quiet hours of a user and channel, replacing any previous ones.
+func (p *PostgresStore) SaveQuietHours(ctx context.Context, quiet *domain.QuietHours) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start", "end", "bundle", "allow_security", "updated_at"}),
+	}).Create(quiet).Error
+}
+
+func (p *PostgresStore) DeleteQuietHours(ctx context.Context, userID uuid.UUID, channel string) error {
+	db := getDB(ctx, p)
+	return db.WithContext(ctx).Where("user_id = ? AND channel = ?", userID, channel).Delete(&domain.QuietHours{}).Error
+}
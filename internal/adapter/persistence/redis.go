@@ -2,13 +2,41 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mackb/releaseradar/pkg/idempotency"
 	"github.com/redis/go-redis/v9"
 )
 
+// Values of idempotency keys: a lease while the operation runs, the result once it completed.
+const (
+	idempotencyInProgress = "in_progress:"
+	idempotencyCompleted  = "completed:"
+)
+
+// completeScript stores the result (ARGV[2], kept for ARGV[3] ms) if the key still holds the
+// lease ARGV[1].
+var completeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// releaseScript deletes the key if it still holds the lease ARGV[1].
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
 type RedisIdempotencyStorage struct {
 	client *redis.Client
 }
@@ -17,11 +45,49 @@ func NewRedisIdempotencyStorage(redisClient *redis.Client) idempotency.Storage {
 	return &RedisIdempotencyStorage{client: redisClient}
 }
 
-func (r *RedisIdempotencyStorage) CheckAndSet(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	// SETNX (Set if Not Exists) returns 1 if the key was set, 0 if it already existed.
-	set, err := r.client.SetNX(ctx, key, true, expiration).Result()
+func (r *RedisIdempotencyStorage) Acquire(ctx context.Context, key string, ttl time.Duration) (idempotency.Lease, error) {
+	token := uuid.NewString()
+	// SETNX (Set if Not Exists) takes the lease only if nobody holds it or completed the operation
+	set, err := r.client.SetNX(ctx, key, idempotencyInProgress+token, ttl).Result()
+	if err != nil {
+		return idempotency.Lease{}, fmt.Errorf("failed to setnx key in redis: %w", err)
+	}
+	if set {
+		return idempotency.Lease{Token: token}, nil
+	}
+
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired in between; report it as in progress and let the caller come back
+		return idempotency.Lease{}, nil
+	}
+	if err != nil {
+		return idempotency.Lease{}, fmt.Errorf("failed to get key from redis: %w", err)
+	}
+	if result, ok := strings.CutPrefix(value, idempotencyCompleted); ok {
+		return idempotency.Lease{Completed: true, Result: []byte(result)}, nil
+	}
+	return idempotency.Lease{}, nil
+}
+
+func (r *RedisIdempotencyStorage) Complete(ctx context.Context, key, token string, result []byte, ttl time.Duration) error {
+	ok, err := completeScript.Run(ctx, r.client, []string{key}, idempotencyInProgress+token, idempotencyCompleted+string(result), ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to complete key in redis: %w", err)
+	}
+	if ok == 0 {
+		return idempotency.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *RedisIdempotencyStorage) Release(ctx context.Context, key, token string) error {
+	ok, err := releaseScript.Run(ctx, r.client, []string{key}, idempotencyInProgress+token).Int()
 	if err != nil {
-		return false, fmt.Errorf("failed to setnx key in redis: %w", err)
+		return fmt.Errorf("failed to release key in redis: %w", err)
+	}
+	if ok == 0 {
+		return idempotency.ErrLeaseLost
 	}
-	return set, nil
+	return nil
 }
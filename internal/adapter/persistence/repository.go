@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mackb/releaseradar/internal/domain"
@@ -11,6 +12,8 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByTelegramChatID(ctx context.Context, chatID int64) (*domain.User, error)
+	UpdateUser(ctx context.Context, user *domain.User) error
 }
 
 type RepoRepository interface {
@@ -19,12 +22,17 @@ type RepoRepository interface {
 	GetRepoByOwnerAndName(ctx context.Context, owner, name string) (*domain.Repo, error)
 	UpdateRepo(ctx context.Context, repo *domain.Repo) error
 	ListRepos(ctx context.Context, userID uuid.UUID) ([]domain.Repo, error)
+	ListActiveRepos(ctx context.Context) ([]domain.Repo, error)
+	ListReposByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Repo, error)
 }
 
 type SubscriptionRepository interface {
 	CreateSubscription(ctx context.Context, sub *domain.Subscription) error
 	GetSubscription(ctx context.Context, repoID, userID uuid.UUID, channel string) (*domain.Subscription, error)
+	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *domain.Subscription) error
 	DeleteSubscription(ctx context.Context, repoID, userID uuid.UUID, channel string) error
+	DeleteSubscriptionByID(ctx context.Context, id uuid.UUID) error
 	ListSubscriptionsByRepoID(ctx context.Context, repoID uuid.UUID) ([]domain.Subscription, error)
 	ListSubscriptionsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error)
 }
@@ -34,15 +42,45 @@ type ReleaseRepository interface {
 	GetReleaseByID(ctx context.Context, id uuid.UUID) (*domain.Release, error)
 	GetReleaseByRepoIDAndTag(ctx context.Context, repoID uuid.UUID, tag string) (*domain.Release, error)
 	ListReleasesByRepoID(ctx context.Context, repoID uuid.UUID) ([]domain.Release, error)
+	GetPreviousRelease(ctx context.Context, repoID uuid.UUID, before time.Time) (*domain.Release, error)
+	UpdateRelease(ctx context.Context, release *domain.Release) error
+	CreateReleaseRevision(ctx context.Context, revision *domain.ReleaseRevision) error
+	GetReleaseRevisionByID(ctx context.Context, id uuid.UUID) (*domain.ReleaseRevision, error)
+	ListReleaseRevisions(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseRevision, error)
+	ReplaceReleaseAssets(ctx context.Context, releaseID uuid.UUID, assets []domain.ReleaseAsset) error
+	ListReleaseAssets(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseAsset, error)
+	ListReleasesWithoutDeliveries(ctx context.Context, since time.Time) ([]domain.Release, error)
 }
 
 type DeliveryRepository interface {
 	CreateDelivery(ctx context.Context, delivery *domain.Delivery) error
-	UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status, lastError string, attempt int) error
-	ListPendingDeliveries(ctx context.Context) ([]domain.Delivery, error)
+	UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, claimedBy, status, lastError string, attempt int) error
+	RecordDeliveryAttempt(ctx context.Context, id uuid.UUID, claimedBy, status, lastError string, attempt, responseCode int, nextAttemptAt *time.Time) error
+	ClaimDeliveries(ctx context.Context, workerID string, batchSize int, leaseDuration time.Duration) ([]domain.Delivery, error)
+	ClaimDeliveriesByID(ctx context.Context, workerID string, ids []uuid.UUID, leaseDuration time.Duration) ([]domain.Delivery, error)
+	ListDeliveriesByStatus(ctx context.Context, status string, userID *uuid.UUID, limit int) ([]domain.Delivery, error)
+	GetDeliveryByID(ctx context.Context, id uuid.UUID) (*domain.Delivery, error)
+	RequeueDelivery(ctx context.Context, id uuid.UUID) error
+	ListDueDeliveries(ctx context.Context, status string, now time.Time) ([]domain.Delivery, error)
+	ScheduleDeliveries(ctx context.Context, ids []uuid.UUID, status string, scheduledAt *time.Time) error
 	GetDelivery(ctx context.Context, releaseID, userID uuid.UUID, channel string) (*domain.Delivery, error)
 }
 
+// TelegramTopicRepository stores the forum topics created for repos in Telegram chats.
+type TelegramTopicRepository interface {
+	GetTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) (*domain.TelegramTopic, error)
+	SaveTelegramTopic(ctx context.Context, topic *domain.TelegramTopic) error
+	DeleteTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) error
+}
+
+// QuietHoursRepository stores users' quiet hours, by user and channel.
+type QuietHoursRepository interface {
+	GetQuietHours(ctx context.Context, userID uuid.UUID, channel string) (*domain.QuietHours, error)
+	ListQuietHours(ctx context.Context, userID uuid.UUID) ([]domain.QuietHours, error)
+	SaveQuietHours(ctx context.Context, quiet *domain.QuietHours) error
+	DeleteQuietHours(ctx context.Context, userID uuid.UUID, channel string) error
+}
+
 type Transactor interface {
 	WithinTransaction(ctx context.Context, txFunc func(ctx context.Context) error) error
 }
@@ -54,5 +92,7 @@ type Store interface {
 	SubscriptionRepository
 	ReleaseRepository
 	DeliveryRepository
+	TelegramTopicRepository
+	QuietHoursRepository
 	Transactor
 }
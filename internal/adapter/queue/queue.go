@@ -0,0 +1,32 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message announces a delivery that is ready to be sent. Postgres stays the source of truth:
+// a message only says where to look, and consumers check the delivery's state before sending.
+type Message struct {
+	ID         string // Queue entry ID, used to acknowledge the message
+	DeliveryID uuid.UUID
+}
+
+// Publisher announces deliveries to the notifier.
+type Publisher interface {
+	PublishDeliveries(ctx context.Context, ids []uuid.UUID) error
+}
+
+// Consumer reads announced deliveries as a member of a consumer group: each message goes to
+// one consumer and stays pending until acknowledged.
+type Consumer interface {
+	// Read waits up to block for new messages.
+	Read(ctx context.Context, count int, block time.Duration) ([]Message, error)
+	// ClaimStale takes over messages another consumer left unacknowledged for minIdle, e.g.
+	// because it crashed while sending.
+	ClaimStale(ctx context.Context, minIdle time.Duration, count int) ([]Message, error)
+	// Ack acknowledges handled messages.
+	Ack(ctx context.Context, ids ...string) error
+}
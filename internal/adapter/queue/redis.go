@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxStreamLength is roughly how many entries the stream keeps; older ones are trimmed.
+const maxStreamLength = 100000
+
+// RedisStream is a Publisher and Consumer on a Redis Stream with a consumer group.
+type RedisStream struct {
+	client   *redis.Client
+	stream   string
+	group    string
+	consumer string
+
+	mu          sync.Mutex
+	claimCursor string // Where the next XAUTOCLAIM scan starts
+}
+
+func NewRedisStream(client *redis.Client, stream, group, consumer string) *RedisStream {
+	return &RedisStream{client: client, stream: stream, group: group, consumer: consumer, claimCursor: "0-0"}
+}
+
+// EnsureGroup creates the stream and its consumer group unless they exist. A new group
+// starts with the entries added from now on.
+func (s *RedisStream) EnsureGroup(ctx context.Context) error {
+	err := s.client.XGroupCreateMkStream(ctx, s.stream, s.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on stream %s: %w", s.group, s.stream, err)
+	}
+	return nil
+}
+
+func (s *RedisStream) PublishDeliveries(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pipe := s.client.Pipeline()
+	for _, id := range ids {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.stream,
+			MaxLen: maxStreamLength,
+			Approx: true,
+			Values: map[string]interface{}{"delivery_id": id.String()},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add deliveries to stream %s: %w", s.stream, err)
+	}
+	return nil
+}
+
+func (s *RedisStream) Read(ctx context.Context, count int, block time.Duration) ([]Message, error) {
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.consumer,
+		Streams:  []string{s.stream, ">"},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from stream %s: %w", s.stream, err)
+	}
+
+	var messages []Message
+	for _, stream := range streams {
+		messages = append(messages, s.parse(ctx, stream.Messages)...)
+	}
+	return messages, nil
+}
+
+func (s *RedisStream) ClaimStale(ctx context.Context, minIdle time.Duration, count int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, next, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.stream,
+		Group:    s.group,
+		Consumer: s.consumer,
+		MinIdle:  minIdle,
+		Start:    s.claimCursor,
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim stale entries of stream %s: %w", s.stream, err)
+	}
+	// The scan resumes where it stopped, and starts over once it went through the pending list
+	s.claimCursor = next
+	return s.parse(ctx, entries), nil
+}
+
+func (s *RedisStream) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.client.XAck(ctx, s.stream, s.group, ids...).Err(); err != nil {
+		return fmt.Errorf("failed to ack entries of stream %s: %w", s.stream, err)
+	}
+	return nil
+}
+
+// parse turns stream entries into messages. Malformed entries cannot be handled, so they are
+// acknowledged and dropped rather than claimed over and over.
+func (s *RedisStream) parse(ctx context.Context, entries []redis.XMessage) []Message {
+	messages := make([]Message, 0, len(entries))
+	for _, entry := range entries {
+		raw, _ := entry.Values["delivery_id"].(string)
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			logger.L().Sugar().Warnf("RedisStream.parse: dropping malformed entry %s of stream %s: %v", entry.ID, s.stream, entry.Values)
+			if err := s.Ack(ctx, entry.ID); err != nil {
+				logger.L().Sugar().Warnf("RedisStream.parse: %v", err)
+			}
+			continue
+		}
+		messages = append(messages, Message{ID: entry.ID, DeliveryID: id})
+	}
+	return messages
+}
@@ -0,0 +1,98 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+// Block Kit limits, see https://api.slack.com/reference/block-kit/blocks
+const (
+	maxHeaderLength  = 150
+	maxExcerptLength = 1500
+)
+
+type payload struct {
+	Text   string  `json:"text"` // Fallback for notifications and clients without Block Kit
+	Blocks []block `json:"blocks"`
+}
+
+type block struct {
+	Type     string    `json:"type"`
+	Text     *text     `json:"text,omitempty"`
+	Fields   []text    `json:"fields,omitempty"`
+	Elements []element `json:"elements,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type element struct {
+	Type string `json:"type"`
+	Text *text  `json:"text,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+func buildPayload(msg *notify.Message) payload {
+	summary := notify.Summarize(msg)
+
+	if msg.Release == nil {
+		// Repo-level notices and digests: the headline, and for digests the list of updates
+		blocks := []block{{Type: "section", Text: mrkdwn(escape(summary.Headline))}}
+		if details := strings.TrimSpace(summary.Details); details != "" {
+			blocks = append(blocks, block{Type: "section", Text: mrkdwn(escape(notify.Excerpt(details, maxExcerptLength)))})
+		}
+		return payload{Text: summary.Headline, Blocks: blocks}
+	}
+
+	repoName := escape(summary.RepoName)
+	if summary.RepoURL != "" {
+		repoName = "<" + summary.RepoURL + "|" + repoName + ">"
+	}
+	fields := []text{
+		*mrkdwn("*Repository*\n" + repoName),
+		*mrkdwn("*Tag*\n`" + escape(msg.Release.Tag) + "`"),
+		*mrkdwn("*Title*\n" + escape(summary.Title)),
+	}
+	if summary.Type != domain.ReleaseTypeUnknown {
+		fields = append(fields, *mrkdwn("*Type*\n" + string(summary.Type)))
+	}
+	if !summary.Published.IsZero() {
+		// Slack renders <!date^...> in each reader's timezone, falling back to the UTC text
+		fields = append(fields, *mrkdwn(fmt.Sprintf("*Published*\n<!date^%d^{date_short_pretty} {time}|%s>",
+			summary.Published.Unix(), summary.Published.UTC().Format(notify.PublishedLayout))))
+	}
+	if summary.Author != "" {
+		fields = append(fields, *mrkdwn("*Author*\n<" + summary.AuthorURL + "|" + escape(summary.Author) + ">"))
+	}
+
+	blocks := []block{
+		{Type: "header", Text: &text{Type: "plain_text", Text: notify.Excerpt(summary.Headline, maxHeaderLength)}},
+		{Type: "section", Fields: fields},
+	}
+	if excerpt := strings.TrimSpace(summary.Details); excerpt != "" {
+		blocks = append(blocks, block{Type: "section", Text: mrkdwn(escape(notify.Excerpt(excerpt, maxExcerptLength)))})
+	}
+	if summary.Link != "" {
+		blocks = append(blocks, block{Type: "actions", Elements: []element{{
+			Type: "button",
+			Text: &text{Type: "plain_text", Text: "View release"},
+			URL:  summary.Link,
+		}}})
+	}
+
+	return payload{Text: fmt.Sprintf("%s: %s", summary.Headline, summary.Title), Blocks: blocks}
+}
+
+func mrkdwn(s string) *text {
+	return &text{Type: "mrkdwn", Text: s}
+}
+
+// escape encodes the characters Slack treats as control sequences in mrkdwn.
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,110 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 5 * time.Second
+)
+
+// goneErrors are the webhook errors meaning it will never accept messages again: it was
+// revoked, or its channel or workspace is gone.
+var goneErrors = map[string]bool{
+	"invalid_token":       true,
+	"no_service":          true,
+	"no_service_id":       true,
+	"no_team":             true,
+	"team_disabled":       true,
+	"channel_not_found":   true,
+	"channel_is_archived": true,
+}
+
+type notifier struct {
+	httpClient *http.Client
+}
+
+// NewNotifier returns a notify.Notifier for "slack:<incoming webhook URL>" channels.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient}
+}
+
+func (n *notifier) Validate(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "https" || u.Host != "hooks.slack.com" || !strings.HasPrefix(u.Path, "/services/") {
+		return fmt.Errorf("%q is not a Slack incoming webhook URL", webhookURL)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, webhookURL string, msg *notify.Message) (notify.Result, error) {
+	payload, err := json.Marshal(buildPayload(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode slack payload: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, err := n.post(ctx, webhookURL, payload)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("slack webhook rate limited, retrying in %s", retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+// post sends one webhook request. A non-zero duration means Slack rate-limited the request
+// and asked us to retry after it.
+func (n *notifier) post(ctx context.Context, webhookURL string, payload []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("slack client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	// Slack answers errors such as invalid_payload, no_service or channel_is_archived in the body
+	detail := strings.TrimSpace(string(body))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return result, notify.RetryAfter(resp.Header, defaultRetryAfter), fmt.Errorf("slack client error: rate limited")
+	case goneErrors[detail] || resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return result, 0, fmt.Errorf("slack client error: %w: status %d: %s", notify.ErrGone, resp.StatusCode, detail)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return result, 0, fmt.Errorf("slack client error: %w: status %d: %s", notify.ErrPermanent, resp.StatusCode, detail)
+	case resp.StatusCode != http.StatusOK:
+		return result, 0, fmt.Errorf("slack client error: status %d: %s", resp.StatusCode, detail)
+	}
+	return result, 0, nil
+}
@@ -0,0 +1,174 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+)
+
+func TestMain(m *testing.M) {
+	notifytest.Main(m)
+}
+
+func testMessage() *notify.Message {
+	return notifytest.Message("Faster builds & <smaller> binaries")
+}
+
+func TestSendOK(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	result, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+}
+
+func TestSendBlockKitBody(t *testing.T) {
+	var got payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	if _, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if got.Text != "New release: golang/go v1.2.0: Go 1.2" {
+		t.Errorf("fallback text = %q", got.Text)
+	}
+	if len(got.Blocks) != 4 {
+		t.Fatalf("got %d blocks, want header, fields, excerpt and actions: %+v", len(got.Blocks), got.Blocks)
+	}
+
+	header := got.Blocks[0]
+	if header.Type != "header" || header.Text == nil || header.Text.Text != "New release: golang/go v1.2.0" {
+		t.Errorf("header block = %+v", header)
+	}
+
+	fields := got.Blocks[1]
+	if fields.Type != "section" {
+		t.Errorf("fields block type = %q, want section", fields.Type)
+	}
+	var texts []string
+	for _, field := range fields.Fields {
+		texts = append(texts, field.Text)
+	}
+	joined := strings.Join(texts, "\n")
+	for _, want := range []string{
+		"*Repository*\n<https://github.com/golang/go|golang/go>",
+		"*Tag*\n`v1.2.0`",
+		"*Title*\nGo 1.2",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("fields missing %q:\n%s", want, joined)
+		}
+	}
+
+	excerpt := got.Blocks[2]
+	if excerpt.Type != "section" || excerpt.Text == nil || excerpt.Text.Text != "Faster builds &amp; &lt;smaller&gt; binaries" {
+		t.Errorf("excerpt block = %+v", excerpt)
+	}
+
+	actions := got.Blocks[3]
+	if actions.Type != "actions" || len(actions.Elements) != 1 {
+		t.Fatalf("actions block = %+v", actions)
+	}
+	button := actions.Elements[0]
+	if button.Type != "button" || button.Text == nil || button.Text.Text != "View release" || button.URL != "https://github.com/golang/go/releases/tag/v1.2.0" {
+		t.Errorf("button = %+v", button)
+	}
+}
+
+func TestSendHonoursRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	result, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("retried after %s, want at least the 200ms Slack asked for", elapsed)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError", err)
+	}
+	if retryAfter.After != 10*time.Minute {
+		t.Errorf("After = %s, want 10m0s", retryAfter.After)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1: a long wait is left to the notifier", n)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusInternalServerError, Body: "rollup_error"},
+		{Name: "invalid payload", Status: http.StatusBadRequest, Body: "invalid_payload", Permanent: true},
+		{Name: "no service", Status: http.StatusNotFound, Body: "no_service", Permanent: true, Gone: true},
+		{Name: "archived channel", Status: http.StatusGone, Body: "channel_is_archived", Permanent: true, Gone: true},
+		{Name: "revoked token", Status: http.StatusForbidden, Body: "invalid_token", Permanent: true, Gone: true},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		result, err := NewNotifier(http.DefaultClient).Send(context.Background(), serverURL, testMessage())
+		if result.StatusCode != tc.Status {
+			t.Errorf("StatusCode = %d, want %d", result.StatusCode, tc.Status)
+		}
+		if err != nil && !strings.Contains(err.Error(), tc.Body) {
+			t.Errorf("error %q does not mention %q", err, tc.Body)
+		}
+		return err
+	})
+}
@@ -0,0 +1,88 @@
+package teams
+
+import (
+	"strings"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+// Adaptive Cards render in Teams from schema version 1.4 on; very long cards are cut off by
+// the client, so the notes excerpt stays short.
+const (
+	cardVersion      = "1.4"
+	maxExcerptLength = 2000
+)
+
+type payload struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string `json:"contentType"`
+	Content     card   `json:"content"`
+}
+
+type card struct {
+	Schema  string    `json:"$schema"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Body    []element `json:"body"`
+	Actions []action  `json:"actions,omitempty"`
+}
+
+type element struct {
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Weight string `json:"weight,omitempty"`
+	Wrap   bool   `json:"wrap,omitempty"`
+	Facts  []fact `json:"facts,omitempty"`
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type action struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func buildPayload(msg *notify.Message) payload {
+	summary := notify.Summarize(msg)
+
+	body := []element{{Type: "TextBlock", Text: escape(summary.Headline), Size: "Medium", Weight: "Bolder", Wrap: true}}
+	if msg.Release != nil {
+		body = append(body, element{Type: "FactSet", Facts: []fact{
+			{Title: "Repository", Value: escape(summary.RepoName)},
+			{Title: "Tag", Value: escape(msg.Release.Tag)},
+			{Title: "Title", Value: escape(summary.Title)},
+		}})
+	}
+	if details := strings.TrimSpace(summary.Details); details != "" {
+		body = append(body, element{Type: "TextBlock", Text: escape(notify.Excerpt(details, maxExcerptLength)), Wrap: true})
+	}
+
+	c := card{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: cardVersion,
+		Body:    body,
+	}
+	if summary.Link != "" {
+		c.Actions = []action{{Type: "Action.OpenUrl", Title: "View release", URL: summary.Link}}
+	}
+
+	return payload{
+		Type:        "message",
+		Attachments: []attachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: c}},
+	}
+}
+
+// escape neutralises the Markdown subset TextBlocks render, so release notes show verbatim.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`").Replace(s)
+}
@@ -0,0 +1,106 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 5 * time.Second
+)
+
+type notifier struct {
+	httpClient *http.Client
+}
+
+// NewNotifier returns a notify.Notifier for "teams:<incoming webhook URL>" channels. Both
+// Office 365 connector webhooks and Power Automate workflow URLs accept the Adaptive Card payload.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient}
+}
+
+func (n *notifier) Validate(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	host := strings.ToLower(u.Hostname())
+	knownHost := strings.HasSuffix(host, ".webhook.office.com") || strings.HasSuffix(host, ".logic.azure.com")
+	if u.Scheme != "https" || !knownHost {
+		return fmt.Errorf("%q is not a Microsoft Teams webhook URL", webhookURL)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, webhookURL string, msg *notify.Message) (notify.Result, error) {
+	payload, err := json.Marshal(buildPayload(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode teams payload: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, err := n.post(ctx, webhookURL, payload)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("teams webhook rate limited, retrying in %s", retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+// post sends one webhook request. A non-zero duration means Teams throttled the request
+// and asked us to retry after it.
+func (n *notifier) post(ctx context.Context, webhookURL string, payload []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create teams request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("teams client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return result, notify.RetryAfter(resp.Header, defaultRetryAfter), fmt.Errorf("teams client error: rate limited")
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		// The connector or workflow was removed from the channel
+		return result, 0, fmt.Errorf("teams client error: %w: webhook no longer exists", notify.ErrGone)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// Malformed card, or a workflow whose signature or permissions no longer match
+		return result, 0, fmt.Errorf("teams client error: %w: status %d: %s", notify.ErrPermanent, resp.StatusCode, strings.TrimSpace(string(body)))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return result, 0, fmt.Errorf("teams client error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Legacy connectors report some failures with 200 and an error text instead of "1"
+	if text := strings.TrimSpace(string(body)); text != "" && text != "1" && strings.Contains(strings.ToLower(text), "error") {
+		return result, 0, fmt.Errorf("teams client error: %s", text)
+	}
+	return result, 0, nil
+}
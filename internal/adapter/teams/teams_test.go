@@ -0,0 +1,124 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/notify/notifytest"
+)
+
+func TestMain(m *testing.M) {
+	notifytest.Main(m)
+}
+
+func testMessage() *notify.Message {
+	return notifytest.Message("Faster *builds*")
+}
+
+func TestSendAdaptiveCard(t *testing.T) {
+	var got payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, "1")
+	}))
+	defer server.Close()
+
+	result, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+
+	if got.Type != "message" || len(got.Attachments) != 1 {
+		t.Fatalf("payload = %+v, want a message with one attachment", got)
+	}
+	attachment := got.Attachments[0]
+	if attachment.ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %q", attachment.ContentType)
+	}
+	c := attachment.Content
+	if c.Type != "AdaptiveCard" || c.Version != cardVersion {
+		t.Errorf("card type %q version %q, want AdaptiveCard %s", c.Type, c.Version, cardVersion)
+	}
+	if len(c.Body) != 3 {
+		t.Fatalf("got %d body elements, want headline, facts and notes: %+v", len(c.Body), c.Body)
+	}
+	if c.Body[0].Text != "New release: golang/go v1.2.0" {
+		t.Errorf("headline = %q", c.Body[0].Text)
+	}
+	wantFacts := []fact{{"Repository", "golang/go"}, {"Tag", "v1.2.0"}, {"Title", "Go 1.2"}}
+	if c.Body[1].Type != "FactSet" || len(c.Body[1].Facts) != len(wantFacts) {
+		t.Fatalf("facts = %+v", c.Body[1])
+	}
+	for i, want := range wantFacts {
+		if c.Body[1].Facts[i] != want {
+			t.Errorf("fact %d = %+v, want %+v", i, c.Body[1].Facts[i], want)
+		}
+	}
+	if c.Body[2].Text != `Faster \*builds\*` {
+		t.Errorf("notes = %q, want Markdown escaped", c.Body[2].Text)
+	}
+	if len(c.Actions) != 1 || c.Actions[0].Type != "Action.OpenUrl" || c.Actions[0].URL != "https://github.com/golang/go/releases/tag/v1.2.0" {
+		t.Errorf("actions = %+v", c.Actions)
+	}
+}
+
+func TestSendRetriesAfter429(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0.1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, "1")
+	}))
+	defer server.Close()
+
+	if _, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "300")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != 5*time.Minute {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 5m", err)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	notifytest.RunErrorCases(t, []notifytest.ErrorCase{
+		{Name: "server error", Status: http.StatusBadGateway},
+		{Name: "bad card", Status: http.StatusBadRequest, Body: "Bad payload", Permanent: true},
+		{Name: "unauthorized", Status: http.StatusUnauthorized, Permanent: true},
+		{Name: "forbidden", Status: http.StatusForbidden, Permanent: true},
+		{Name: "removed connector", Status: http.StatusNotFound, Permanent: true, Gone: true},
+		{Name: "error in 200 body", Status: http.StatusOK, Body: "Microsoft Teams endpoint returned HTTP error 413"},
+	}, func(t *testing.T, serverURL string, tc notifytest.ErrorCase) error {
+		_, err := NewNotifier(http.DefaultClient).Send(context.Background(), serverURL, testMessage())
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SplitAddress splits a channel address, "<chat>" or "<chat>/<thread>" for a forum topic,
+// into the chat and the topic's message_thread_id, which is 0 for none.
+func SplitAddress(address string) (chat string, threadID int) {
+	chat, thread, found := strings.Cut(address, "/")
+	if !found {
+		return address, 0
+	}
+	threadID, err := strconv.Atoi(thread)
+	if err != nil {
+		return chat, 0
+	}
+	return chat, threadID
+}
+
+// Address joins a chat and a forum topic into a channel address.
+func Address(chat string, threadID int) string {
+	if threadID == 0 {
+		return chat
+	}
+	return chat + "/" + strconv.Itoa(threadID)
+}
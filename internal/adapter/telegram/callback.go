@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/pkg/signedtoken"
+)
+
+// Action is what an inline button on a notification does when pressed.
+type Action byte
+
+const (
+	ActionMute        Action = 'm' // Mute the subscription for a week
+	ActionUnsubscribe Action = 'u' // Remove the subscription
+	ActionMajorOnly   Action = 'j' // Only notify about major releases from now on
+	ActionFullNotes   Action = 'n' // Post the complete release notes; the ID is a release ID
+)
+
+// NoopCallback is the data of buttons that only show a confirmation.
+const NoopCallback = "noop"
+
+// Button is an inline keyboard button sending Data back in a callback query when pressed.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Callback is a press of an inline button.
+type Callback struct {
+	ID        string // Passed back to AnswerCallback
+	ChatID    int64
+	ThreadID  int // Forum topic of the message; 0 outside topics
+	MessageID int // Message carrying the button
+	Data      string
+}
+
+// CallbackData signs an action on a subscription or release. Telegram limits callback data to
+// 64 bytes; an action and an ID sign to 46.
+func CallbackData(secret []byte, action Action, id uuid.UUID) string {
+	payload := append([]byte{byte(action)}, id[:]...)
+	return signedtoken.Sign(secret, payload)
+}
+
+// ParseCallbackData verifies data produced by CallbackData and returns the action and ID.
+func ParseCallbackData(secret []byte, data string) (Action, uuid.UUID, error) {
+	payload, err := signedtoken.Verify(secret, data)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	if len(payload) != 1+len(uuid.UUID{}) {
+		return 0, uuid.Nil, signedtoken.ErrInvalidToken
+	}
+	id, err := uuid.FromBytes(payload[1:])
+	if err != nil {
+		return 0, uuid.Nil, signedtoken.ErrInvalidToken
+	}
+	return Action(payload[0]), id, nil
+}
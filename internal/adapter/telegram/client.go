@@ -4,6 +4,26 @@ import (
 	"context"
 )
 
+// Client talks to the Bot API. Chat IDs may address a forum topic as "<chat>/<thread>" (see
+// SplitAddress).
 type Client interface {
 	SendMessage(ctx context.Context, chatID string, text string) error
+	// SendMessageWithButtons sends a message with rows of inline buttons below it.
+	SendMessageWithButtons(ctx context.Context, chatID string, text string, buttons [][]Button) error
+	// SendDocument uploads content as a file named fileName, with an HTML caption.
+	SendDocument(ctx context.Context, chatID string, fileName string, content []byte, caption string) error
+
+	// EditButtons replaces the inline buttons of a sent message.
+	EditButtons(ctx context.Context, chatID string, messageID int, buttons [][]Button) error
+	// CreateTopic creates a forum topic in a supergroup and returns its message_thread_id.
+	CreateTopic(ctx context.Context, chatID string, name string) (int, error)
+	// AnswerCallback acknowledges a button press, showing text as a short notice if not empty.
+	AnswerCallback(ctx context.Context, callbackID string, text string) error
+
+	// PollUpdates receives bot commands and button presses by long polling until ctx is done.
+	PollUpdates(ctx context.Context, handle func(context.Context, Update)) error
+	// SetWebhook switches the bot to receiving updates at url instead of by polling.
+	SetWebhook(ctx context.Context, url string, secretToken string) error
+	// ParseUpdate decodes the body of a webhook request.
+	ParseUpdate(data []byte) (Update, error)
 }
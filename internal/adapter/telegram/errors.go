@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gobotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+// Errors for chats the bot can no longer post to. They wrap notify.ErrGone, so deliveries to
+// them are not retried and the subscriptions are disabled.
+var (
+	ErrBotBlocked   = fmt.Errorf("%w: bot was blocked by the user", notify.ErrGone)
+	ErrBotKicked    = fmt.Errorf("%w: bot is not a member of the chat", notify.ErrGone)
+	ErrChatNotFound = fmt.Errorf("%w: chat not found", notify.ErrGone)
+	// ErrTopicNotFound is returned for messages to a forum topic that was deleted.
+	ErrTopicNotFound = fmt.Errorf("%w: forum topic not found", notify.ErrGone)
+)
+
+// rateLimitedError is a 429 from the Bot API, asking us to wait retryAfter before the next call.
+type rateLimitedError struct {
+	retryAfter time.Duration
+	message    string
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %s", e.retryAfter, e.message)
+}
+
+// classify turns a Bot API error into one of the typed errors above, a *notify.MovedError for
+// groups upgraded to supergroups, a *rateLimitedError, or a notify.ErrPermanent for other
+// requests Telegram rejected. Transport and server errors are returned unchanged.
+func classify(err error) error {
+	var apiErr *gobotapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	message := strings.ToLower(apiErr.Message)
+	switch {
+	case apiErr.MigrateToChatID != 0:
+		return &notify.MovedError{Address: strconv.FormatInt(apiErr.MigrateToChatID, 10)}
+	case apiErr.Code == http.StatusTooManyRequests:
+		return &rateLimitedError{retryAfter: time.Duration(apiErr.RetryAfter) * time.Second, message: apiErr.Message}
+	case apiErr.Code == http.StatusForbidden && (strings.Contains(message, "kicked") || strings.Contains(message, "not a member")):
+		return fmt.Errorf("%w: %s", ErrBotKicked, apiErr.Message)
+	case apiErr.Code == http.StatusForbidden:
+		// Blocked by the user, or the user deleted their account
+		return fmt.Errorf("%w: %s", ErrBotBlocked, apiErr.Message)
+	case apiErr.Code == http.StatusBadRequest && strings.Contains(message, "thread not found"):
+		return fmt.Errorf("%w: %s", ErrTopicNotFound, apiErr.Message)
+	case apiErr.Code == http.StatusBadRequest && strings.Contains(message, "chat not found"):
+		return fmt.Errorf("%w: %s", ErrChatNotFound, apiErr.Message)
+	case apiErr.Code >= 400 && apiErr.Code < 500:
+		return fmt.Errorf("%w: %s", notify.ErrPermanent, apiErr.Message)
+	}
+	return err
+}
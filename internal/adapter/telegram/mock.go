@@ -14,3 +14,43 @@ func (m *MockTelegramClient) SendMessage(ctx context.Context, chatID string, tex
 	args := m.Called(ctx, chatID, text)
 	return args.Error(0)
 }
+
+func (m *MockTelegramClient) SendDocument(ctx context.Context, chatID string, fileName string, content []byte, caption string) error {
+	args := m.Called(ctx, chatID, fileName, content, caption)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) SendMessageWithButtons(ctx context.Context, chatID string, text string, buttons [][]Button) error {
+	args := m.Called(ctx, chatID, text, buttons)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) EditButtons(ctx context.Context, chatID string, messageID int, buttons [][]Button) error {
+	args := m.Called(ctx, chatID, messageID, buttons)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) CreateTopic(ctx context.Context, chatID string, name string) (int, error) {
+	args := m.Called(ctx, chatID, name)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTelegramClient) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	args := m.Called(ctx, callbackID, text)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) PollUpdates(ctx context.Context, handle func(context.Context, Update)) error {
+	args := m.Called(ctx, handle)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) SetWebhook(ctx context.Context, url string, secretToken string) error {
+	args := m.Called(ctx, url, secretToken)
+	return args.Error(0)
+}
+
+func (m *MockTelegramClient) ParseUpdate(data []byte) (Update, error) {
+	args := m.Called(data)
+	return args.Get(0).(Update), args.Error(1)
+}
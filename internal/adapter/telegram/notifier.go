@@ -0,0 +1,188 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/markdown"
+)
+
+const (
+	// maxMessageLength is Telegram's limit on the visible text of a message.
+	maxMessageLength = 4096
+	// maxMessageParts is how many messages a notification may span before the release notes are
+	// attached as a document instead, so very large notes do not flood the chat.
+	maxMessageParts = 4
+)
+
+// chatIDPattern matches numeric chat IDs (negative for groups and channels) and @channel
+// usernames, with an optional forum topic.
+var chatIDPattern = regexp.MustCompile(`^(-?\d+|@[A-Za-z][A-Za-z0-9_]{4,31})(/\d+)?$`)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// TopicStore remembers the forum topic created for each repo in a chat.
+type TopicStore interface {
+	GetTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) (*domain.TelegramTopic, error)
+	SaveTelegramTopic(ctx context.Context, topic *domain.TelegramTopic) error
+	DeleteTelegramTopic(ctx context.Context, chatID string, repoID uuid.UUID) error
+}
+
+type notifier struct {
+	client         Client
+	callbackSecret []byte
+	topics         TopicStore
+}
+
+// NewNotifier exposes a Telegram client as a notify.Notifier for "telegram:<chat>" and
+// "telegram:<chat>/<thread>" channels. Notifications carry inline buttons signed with
+// callbackSecret; without a secret they have none. Subscriptions with RepoTopics get a forum
+// topic per repo, remembered in topics.
+func NewNotifier(client Client, callbackSecret []byte, topics TopicStore) notify.Notifier {
+	return &notifier{client: client, callbackSecret: callbackSecret, topics: topics}
+}
+
+func (n *notifier) Validate(chatID string) error {
+	if !chatIDPattern.MatchString(chatID) {
+		return fmt.Errorf("%q is not a chat ID or @channel username, optionally followed by /<message_thread_id>", chatID)
+	}
+	return nil
+}
+
+func (n *notifier) Send(ctx context.Context, chatID string, msg *notify.Message) (notify.Result, error) {
+	chat, threadID := SplitAddress(chatID)
+	if threadID != 0 || n.topics == nil || msg.Subscription == nil || !msg.Subscription.RepoTopics || msg.Repo == nil {
+		return notify.Result{}, n.deliver(ctx, chatID, msg)
+	}
+
+	// Route the repo into its own forum topic, recreating the topic if it was deleted
+	address, err := n.topicAddress(ctx, chat, msg.Repo)
+	if err != nil {
+		return notify.Result{}, err
+	}
+	err = n.deliver(ctx, address, msg)
+	if errors.Is(err, ErrTopicNotFound) {
+		if err := n.topics.DeleteTelegramTopic(ctx, chat, msg.Repo.ID); err != nil {
+			return notify.Result{}, fmt.Errorf("failed to forget deleted topic: %w", err)
+		}
+		if address, err = n.topicAddress(ctx, chat, msg.Repo); err != nil {
+			return notify.Result{}, err
+		}
+		err = n.deliver(ctx, address, msg)
+	}
+	return notify.Result{}, err
+}
+
+// topicAddress returns the address of the forum topic of repo in chat, creating the topic
+// named "<owner>/<name>" on first use.
+func (n *notifier) topicAddress(ctx context.Context, chat string, repo *domain.Repo) (string, error) {
+	topic, err := n.topics.GetTelegramTopic(ctx, chat, repo.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get topic of %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	if topic != nil {
+		return Address(chat, topic.ThreadID), nil
+	}
+
+	threadID, err := n.client.CreateTopic(ctx, chat, repo.Owner+"/"+repo.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create topic for %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	topic = &domain.TelegramTopic{ChatID: chat, RepoID: repo.ID, ThreadID: threadID, CreatedAt: time.Now()}
+	if err := n.topics.SaveTelegramTopic(ctx, topic); err != nil {
+		return "", fmt.Errorf("failed to save topic of %s/%s: %w", repo.Owner, repo.Name, err)
+	}
+	return Address(chat, threadID), nil
+}
+
+// deliver sends the message, split into parts as needed.
+func (n *notifier) deliver(ctx context.Context, chatID string, msg *notify.Message) error {
+	parts := splitHTML(msg.Text, maxMessageLength)
+	buttons := n.buttons(msg)
+
+	if len(parts) > maxMessageParts && msg.Release != nil && msg.Release.Body != "" {
+		if err := n.client.SendMessageWithButtons(ctx, chatID, parts[0], buttons); err != nil {
+			return err
+		}
+		return sendNotesDocument(ctx, n.client, chatID, msg.Repo, msg.Release)
+	}
+
+	// Parts are sent one by one so they arrive in order; the buttons go under the last one
+	for i, part := range parts {
+		var partButtons [][]Button
+		if i == len(parts)-1 {
+			partButtons = buttons
+		}
+		if err := n.client.SendMessageWithButtons(ctx, chatID, part, partButtons); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buttons builds the inline keyboard of a notification: muting, major-only and unsubscribe
+// act on the subscription, and "Full notes" posts the complete release notes.
+func (n *notifier) buttons(msg *notify.Message) [][]Button {
+	if len(n.callbackSecret) == 0 || msg.Subscription == nil {
+		return nil
+	}
+	sub := msg.Subscription
+
+	subRow := []Button{{Text: "🔕 Mute for 7 days", Data: CallbackData(n.callbackSecret, ActionMute, sub.ID)}}
+	if !sub.MajorOnly {
+		subRow = append(subRow, Button{Text: "Only majors", Data: CallbackData(n.callbackSecret, ActionMajorOnly, sub.ID)})
+	}
+	var notesRow []Button
+	if msg.Release != nil && msg.Release.Body != "" {
+		notesRow = append(notesRow, Button{Text: "📄 Full notes", Data: CallbackData(n.callbackSecret, ActionFullNotes, msg.Release.ID)})
+	}
+	notesRow = append(notesRow, Button{Text: "Unsubscribe", Data: CallbackData(n.callbackSecret, ActionUnsubscribe, sub.ID)})
+
+	return [][]Button{subRow, notesRow}
+}
+
+// SendNotes posts the complete notes of a release, converted from Markdown, in as many
+// messages as needed, or as a document when they would take more than a few messages.
+func SendNotes(ctx context.Context, client Client, chatID string, repo *domain.Repo, release *domain.Release) error {
+	text := fmt.Sprintf("<b>%s</b>\n\n%s", html.EscapeString(notesTitle(repo, release)), markdown.Parse(release.Body).HTML(0, ""))
+	parts := splitHTML(text, maxMessageLength)
+	if len(parts) > maxMessageParts {
+		return sendNotesDocument(ctx, client, chatID, repo, release)
+	}
+
+	for _, part := range parts {
+		if err := client.SendMessage(ctx, chatID, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sendNotesDocument(ctx context.Context, client Client, chatID string, repo *domain.Repo, release *domain.Release) error {
+	caption := "Full release notes for <b>" + html.EscapeString(notesTitle(repo, release)) + "</b>"
+	return client.SendDocument(ctx, chatID, notesFileName(repo, release), []byte(release.Body), caption)
+}
+
+// notesTitle is "<owner>/<name> <tag>", or just the tag if the repo is unknown.
+func notesTitle(repo *domain.Repo, release *domain.Release) string {
+	if repo == nil {
+		return release.Tag
+	}
+	return repo.Owner + "/" + repo.Name + " " + release.Tag
+}
+
+// notesFileName names the release notes document "<owner>-<name>-<tag>.md".
+func notesFileName(repo *domain.Repo, release *domain.Release) string {
+	name := release.Tag
+	if repo != nil {
+		name = repo.Owner + "-" + repo.Name + "-" + name
+	}
+	return unsafeFileChars.ReplaceAllString(name, "_") + ".md"
+}
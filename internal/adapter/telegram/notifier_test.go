@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+func longMessage(body string) *notify.Message {
+	return &notify.Message{
+		Kind:    domain.DeliveryKindRelease,
+		Repo:    &domain.Repo{Owner: "golang", Name: "go"},
+		Release: &domain.Release{Tag: "v1.2.0", Body: body},
+		Text:    strings.Repeat("a long line of release notes\n", 1000),
+	}
+}
+
+func TestSendAttachesLongNotesAsDocument(t *testing.T) {
+	msg := longMessage("# Notes")
+	parts := splitHTML(msg.Text, maxMessageLength)
+	if len(parts) <= maxMessageParts {
+		t.Fatalf("test message spans %d parts, want more than %d", len(parts), maxMessageParts)
+	}
+
+	client := new(MockTelegramClient)
+	client.On("SendMessageWithButtons", mock.Anything, "123", parts[0], mock.Anything).Return(nil).Once()
+	client.On("SendDocument", mock.Anything, "123", "golang-go-v1.2.0.md", []byte("# Notes"), "Full release notes for <b>golang/go v1.2.0</b>").Return(nil).Once()
+
+	if _, err := NewNotifier(client, nil, nil).Send(context.Background(), "123", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	client.AssertExpectations(t)
+}
+
+func TestSendSplitsLongTextWithoutNotes(t *testing.T) {
+	msg := longMessage("")
+	parts := splitHTML(msg.Text, maxMessageLength)
+
+	client := new(MockTelegramClient)
+	for _, part := range parts {
+		client.On("SendMessageWithButtons", mock.Anything, "123", part, mock.Anything).Return(nil).Once()
+	}
+
+	if _, err := NewNotifier(client, nil, nil).Send(context.Background(), "123", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	client.AssertExpectations(t)
+	client.AssertNotCalled(t, "SendDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// atom is the smallest unit a message can be split around: a tag, which takes no space, or
+// one visible character, written as a rune or an HTML entity.
+type atom struct {
+	raw     string
+	tag     string // Lower-case tag name; empty for characters
+	closing bool
+	width   int // Length in UTF-16 code units, which is how Telegram counts
+}
+
+// openTag is a tag that is open at some point of the message, kept to reopen it in the next part.
+type openTag struct {
+	name string
+	raw  string
+}
+
+// splitHTML splits Telegram HTML into parts of at most limit visible characters. It prefers
+// to cut at paragraph breaks and code-block ends, then at line breaks, then between words,
+// and closes and reopens the tags that are open at a cut so every part is well-formed.
+func splitHTML(text string, limit int) []string {
+	atoms := tokenize(text)
+
+	total := 0
+	for _, a := range atoms {
+		total += a.width
+	}
+	if total <= limit {
+		return []string{text}
+	}
+
+	var parts []string
+	var open []openTag
+	for start := skipNewlines(atoms, 0); start < len(atoms); {
+		end, cut := start, start
+		width := 0
+		paragraph, line, space := -1, -1, -1
+		for end < len(atoms) && width+atoms[end].width <= limit {
+			a := atoms[end]
+			width += a.width
+			end++
+			switch {
+			case a.raw == "\n" && end-2 >= start && atoms[end-2].raw == "\n":
+				paragraph = end
+			case a.closing && a.tag == "pre":
+				paragraph = end
+			case a.raw == "\n":
+				line = end
+			case a.raw == " ":
+				space = end
+			}
+		}
+
+		// Only back off to a break in the second half of the part, so parts stay reasonably full
+		half := start + (end-start)/2
+		switch {
+		case end == len(atoms):
+			cut = end
+		case paragraph > half:
+			cut = paragraph
+		case line > half:
+			cut = line
+		case space > half:
+			cut = space
+		default:
+			cut = end
+		}
+		if cut == start {
+			cut = start + 1 // A single character wider than limit; cannot happen for limit >= 2
+		}
+
+		var b strings.Builder
+		for _, tag := range open {
+			b.WriteString(tag.raw)
+		}
+		for _, a := range atoms[start:trimNewlines(atoms, start, cut)] {
+			b.WriteString(a.raw)
+		}
+		for _, a := range atoms[start:cut] {
+			open = track(open, a)
+		}
+		for i := len(open) - 1; i >= 0; i-- {
+			b.WriteString("</" + open[i].name + ">")
+		}
+		parts = append(parts, b.String())
+
+		start = skipNewlines(atoms, cut)
+	}
+	return parts
+}
+
+// tokenize breaks HTML into atoms. Text that is not valid HTML is passed through as characters.
+func tokenize(text string) []atom {
+	var atoms []atom
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				raw := text[i : i+end+1]
+				name := strings.TrimPrefix(raw[1:len(raw)-1], "/")
+				if sp := strings.IndexAny(name, " \t\n"); sp >= 0 {
+					name = name[:sp]
+				}
+				atoms = append(atoms, atom{raw: raw, tag: strings.ToLower(name), closing: raw[1] == '/'})
+				i += end + 1
+				continue
+			}
+		case '&':
+			if end := strings.IndexByte(text[i:], ';'); end > 0 && end < 10 {
+				atoms = append(atoms, atom{raw: text[i : i+end+1], width: 1})
+				i += end + 1
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		width := 1
+		if r > 0xFFFF {
+			width = 2 // Surrogate pair
+		}
+		atoms = append(atoms, atom{raw: text[i : i+size], width: width})
+		i += size
+	}
+	return atoms
+}
+
+// track updates the stack of open tags with a.
+func track(open []openTag, a atom) []openTag {
+	switch {
+	case a.tag == "" || strings.HasSuffix(a.raw, "/>"):
+		return open
+	case !a.closing:
+		return append(open, openTag{name: a.tag, raw: a.raw})
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i].name == a.tag {
+			return append(open[:i], open[i+1:]...)
+		}
+	}
+	return open
+}
+
+func skipNewlines(atoms []atom, i int) int {
+	for i < len(atoms) && atoms[i].raw == "\n" {
+		i++
+	}
+	return i
+}
+
+// trimNewlines returns end moved back over line breaks that would trail the part.
+func trimNewlines(atoms []atom, start, end int) int {
+	for end > start && atoms[end-1].raw == "\n" {
+		end--
+	}
+	return end
+}
@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitHTML(t *testing.T) {
+	const link = `<a href="https://x.org/a?b=1&amp;c=2">`
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"fits", "<b>short</b>", 5, []string{"<b>short</b>"}},
+		{
+			"code block", `<pre><code class="language-go">aaaa bbbb cccc</code></pre>`, 10,
+			[]string{`<pre><code class="language-go">aaaa bbbb </code></pre>`, `<pre><code class="language-go">cccc</code></pre>`},
+		},
+		{
+			"link", "see " + link + "the long link text</a> now", 12,
+			[]string{"see " + link + "the </a>", link + "long link </a>", link + "text</a> now"},
+		},
+		{"entities", "&lt;&lt;&lt;&lt;&lt;&lt;", 3, []string{"&lt;&lt;&lt;", "&lt;&lt;&lt;"}},
+		{"entities fit", "a&amp;b&amp;c", 5, []string{"a&amp;b&amp;c"}},
+		{"astral runes", "😀😀😀", 4, []string{"😀😀", "😀"}},
+		{"astral runes fit", "😀😀😀", 6, []string{"😀😀😀"}},
+		{"paragraph", "aaaaaa\n\nbbb ccc", 12, []string{"aaaaaa", "bbb ccc"}},
+		{"line", "aaa bbb\nccc dddd", 12, []string{"aaa bbb", "ccc dddd"}},
+		{"space", "aaaa bbbb cccc dddd", 12, []string{"aaaa bbbb ", "cccc dddd"}},
+		{"no break", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitHTML(tt.text, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitHTML(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHTMLKeepsPartsWithinLimit(t *testing.T) {
+	text := strings.Repeat("<b>release</b> notes with <i>some <code>x &lt; y</code></i> 🚀\n", 500)
+	parts := splitHTML(text, maxMessageLength)
+	if len(parts) < 2 {
+		t.Fatalf("splitHTML returned %d parts, want several", len(parts))
+	}
+	for i, part := range parts {
+		width := 0
+		var open []openTag
+		for _, a := range tokenize(part) {
+			width += a.width
+			open = track(open, a)
+		}
+		if width > maxMessageLength {
+			t.Errorf("part %d is %d characters long", i, width)
+		}
+		if len(open) != 0 {
+			t.Errorf("part %d leaves %d tags open", i, len(open))
+		}
+	}
+}
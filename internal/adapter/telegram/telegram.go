@@ -2,12 +2,22 @@ package telegram
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	gobotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
 	"github.com/mackb/releaseradar/pkg/logger"
-	"github.com/mackb/releaseradar/pkg/retry"
+)
+
+const (
+	maxAttempts = 3
+	retryDelay  = 2 * time.Second // Doubles after each failed attempt
+	// maxRetryAfter bounds how long a send waits out a 429; longer waits are returned to the
+	// notifier as a *notify.RetryAfterError
+	maxRetryAfter = time.Minute
 )
 
 type telegramClient struct {
@@ -26,17 +36,137 @@ func NewTelegramClient(botToken string) (Client, error) {
 }
 
 func (t *telegramClient) SendMessage(ctx context.Context, chatID string, text string) error {
-	msg := gobotapi.NewMessageToChannel(chatID, text)
-	msg.ParseMode = gobotapi.ModeHTML
+	return t.SendMessageWithButtons(ctx, chatID, text, nil)
+}
 
-	var err error
-	err = retry.Do(3, 2*time.Second, func() error {
-		_, sendErr := t.bot.Send(msg)
-		if sendErr != nil {
-			logger.L().Sugar().Errorf("failed to send telegram message to chat %s: %v", chatID, sendErr)
-			return fmt.Errorf("telegram client error: %w", sendErr)
+// The library predates forum topics, so messages and documents are sent with hand-built
+// parameters that can carry message_thread_id.
+
+func (t *telegramClient) SendMessageWithButtons(ctx context.Context, chatID string, text string, buttons [][]Button) error {
+	params := chatParams(chatID)
+	params["text"] = text
+	params["parse_mode"] = gobotapi.ModeHTML
+	if len(buttons) > 0 {
+		if err := params.AddInterface("reply_markup", keyboard(buttons)); err != nil {
+			return err
+		}
+	}
+
+	return t.send(ctx, chatID, func() error {
+		_, err := t.bot.MakeRequest("sendMessage", params)
+		return err
+	})
+}
+
+func (t *telegramClient) SendDocument(ctx context.Context, chatID string, fileName string, content []byte, caption string) error {
+	params := chatParams(chatID)
+	params.AddNonEmpty("caption", caption)
+	params["parse_mode"] = gobotapi.ModeHTML
+	files := []gobotapi.RequestFile{{Name: "document", Data: gobotapi.FileBytes{Name: fileName, Bytes: content}}}
+
+	return t.send(ctx, chatID, func() error {
+		_, err := t.bot.UploadFiles("sendDocument", params, files)
+		return err
+	})
+}
+
+func (t *telegramClient) EditButtons(ctx context.Context, chatID string, messageID int, buttons [][]Button) error {
+	chat, _ := SplitAddress(chatID) // Message IDs are unique within the whole chat
+	edit := gobotapi.EditMessageReplyMarkupConfig{
+		BaseEdit: gobotapi.BaseEdit{ChannelUsername: chat, MessageID: messageID},
+	}
+	markup := keyboard(buttons)
+	edit.ReplyMarkup = &markup
+
+	return t.send(ctx, chatID, func() error {
+		_, err := t.bot.Request(edit)
+		return err
+	})
+}
+
+func (t *telegramClient) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	if _, err := t.bot.Request(gobotapi.NewCallback(callbackID, text)); err != nil {
+		return fmt.Errorf("telegram client error: %w", classify(err))
+	}
+	return nil
+}
+
+func (t *telegramClient) CreateTopic(ctx context.Context, chatID string, name string) (int, error) {
+	params := gobotapi.Params{"chat_id": chatID, "name": name}
+
+	var topic struct {
+		MessageThreadID int `json:"message_thread_id"`
+	}
+	err := t.send(ctx, chatID, func() error {
+		resp, err := t.bot.MakeRequest("createForumTopic", params)
+		if err != nil {
+			return err
 		}
-		return nil
+		return json.Unmarshal(resp.Result, &topic)
 	})
+	if err != nil {
+		return 0, err
+	}
+	return topic.MessageThreadID, nil
+}
+
+// chatParams addresses a request to a chat, and to a forum topic for "<chat>/<thread>".
+func chatParams(chatID string) gobotapi.Params {
+	chat, threadID := SplitAddress(chatID)
+	params := gobotapi.Params{"chat_id": chat}
+	params.AddNonZero("message_thread_id", threadID)
+	return params
+}
+
+// send makes a Bot API call, waiting out short 429s and retrying other transient errors with
+// backoff. Errors that retrying cannot fix are returned at once. A 429 that is too long to wait
+// out, or is still there after the last attempt, is returned as a *notify.RetryAfterError so
+// that the delivery is not retried before Telegram allows it.
+func (t *telegramClient) send(ctx context.Context, chatID string, call func() error) error {
+	delay := retryDelay
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		sendErr := call()
+		if sendErr == nil {
+			return nil
+		}
+		err = classify(sendErr)
+		logger.L().Sugar().Errorf("failed to send telegram message to chat %s (attempt %d): %v", chatID, attempt, err)
+		if errors.Is(err, notify.ErrPermanent) || attempt == maxAttempts {
+			break
+		}
+
+		wait := delay
+		var limited *rateLimitedError
+		if errors.As(err, &limited) && limited.retryAfter > 0 {
+			if limited.retryAfter > maxRetryAfter {
+				break
+			}
+			wait = limited.retryAfter
+		}
+		if sleepErr := notify.Sleep(ctx, wait); sleepErr != nil {
+			return sleepErr
+		}
+		delay *= 2
+	}
+
+	err = fmt.Errorf("telegram client error: %w", err)
+	var limited *rateLimitedError
+	if errors.As(err, &limited) && limited.retryAfter > 0 {
+		return &notify.RetryAfterError{After: limited.retryAfter, Err: err}
+	}
 	return err
 }
+
+func keyboard(buttons [][]Button) gobotapi.InlineKeyboardMarkup {
+	rows := make([][]gobotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, row := range buttons {
+		var keys []gobotapi.InlineKeyboardButton
+		for _, button := range row {
+			keys = append(keys, gobotapi.NewInlineKeyboardButtonData(button.Text, button.Data))
+		}
+		rows = append(rows, keys)
+	}
+	// Telegram rejects a null keyboard; an empty one removes the buttons
+	return gobotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
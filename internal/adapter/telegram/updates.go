@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	gobotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// pollTimeout is how long a getUpdates call waits for new updates before returning empty.
+const pollTimeout = 30
+
+// allowedUpdates are the update types the bot asks Telegram for.
+var allowedUpdates = []string{"message", "channel_post", "callback_query"}
+
+// Update is a bot command or a button press; other updates leave both nil.
+type Update struct {
+	Command  *Command
+	Callback *Callback
+}
+
+// Command is a bot command sent in a chat, e.g. "/watch owner/repo".
+type Command struct {
+	ChatID   int64
+	ThreadID int    // Forum topic the command was sent in; 0 outside topics
+	ChatType string // "private", "group", "supergroup" or "channel"
+	Name     string // Command without the slash and any @botname suffix, e.g. "watch"
+	Args     string // Text after the command, trimmed
+}
+
+// PollUpdates long-polls getUpdates and passes every command addressed to the bot and every
+// button press to handle, one at a time, until ctx is done. Any webhook is removed first, as
+// Telegram refuses getUpdates while one is set.
+func (t *telegramClient) PollUpdates(ctx context.Context, handle func(context.Context, Update)) error {
+	if _, err := t.bot.MakeRequest("deleteWebhook", nil); err != nil {
+		return fmt.Errorf("telegram client error: failed to delete webhook: %w", err)
+	}
+
+	offset := 0
+	for ctx.Err() == nil {
+		params := gobotapi.Params{}
+		params.AddNonZero("offset", offset)
+		params.AddNonZero("timeout", pollTimeout)
+		if err := params.AddInterface("allowed_updates", allowedUpdates); err != nil {
+			return err
+		}
+
+		// Updates are decoded from the raw response to keep the forum fields the library drops
+		var raws []json.RawMessage
+		resp, err := t.bot.MakeRequest("getUpdates", params)
+		if err == nil {
+			err = json.Unmarshal(resp.Result, &raws)
+		}
+		if err != nil {
+			logger.L().Sugar().Errorf("failed to get telegram updates: %v", err)
+			if sleepErr := notify.Sleep(ctx, 3*time.Second); sleepErr != nil {
+				break
+			}
+			continue
+		}
+
+		for _, raw := range raws {
+			update, threads, err := decodeUpdate(raw)
+			if err != nil {
+				logger.L().Sugar().Errorf("failed to decode telegram update: %v", err)
+				continue
+			}
+			offset = update.UpdateID + 1
+			if u := t.update(update, threads); u.Command != nil || u.Callback != nil {
+				handle(ctx, u)
+			}
+		}
+	}
+	return nil
+}
+
+// SetWebhook asks Telegram to POST updates to url, with secretToken in the
+// X-Telegram-Bot-Api-Secret-Token header of each request.
+func (t *telegramClient) SetWebhook(ctx context.Context, url string, secretToken string) error {
+	params := gobotapi.Params{"url": url}
+	params.AddNonEmpty("secret_token", secretToken)
+	if err := params.AddInterface("allowed_updates", allowedUpdates); err != nil {
+		return err
+	}
+	if _, err := t.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("telegram client error: failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// ParseUpdate decodes an update delivered to the webhook. Updates that are neither commands
+// addressed to the bot nor button presses come back empty.
+func (t *telegramClient) ParseUpdate(data []byte) (Update, error) {
+	update, threads, err := decodeUpdate(data)
+	if err != nil {
+		return Update{}, err
+	}
+	return t.update(update, threads), nil
+}
+
+// threadFields holds the forum topic fields of an update, which the library's types predate.
+type threadFields struct {
+	Message       *topicMessage `json:"message"`
+	CallbackQuery *struct {
+		Message *topicMessage `json:"message"`
+	} `json:"callback_query"`
+}
+
+type topicMessage struct {
+	MessageThreadID int  `json:"message_thread_id"`
+	IsTopicMessage  bool `json:"is_topic_message"`
+}
+
+// threadID returns the forum topic of a message. Replies outside forums carry a thread ID
+// too, so it only counts for topic messages.
+func (m *topicMessage) threadID() int {
+	if m == nil || !m.IsTopicMessage {
+		return 0
+	}
+	return m.MessageThreadID
+}
+
+func decodeUpdate(data []byte) (gobotapi.Update, threadFields, error) {
+	var update gobotapi.Update
+	var threads threadFields
+	if err := json.Unmarshal(data, &update); err != nil {
+		return update, threads, fmt.Errorf("invalid telegram update: %w", err)
+	}
+	if err := json.Unmarshal(data, &threads); err != nil {
+		return update, threads, fmt.Errorf("invalid telegram update: %w", err)
+	}
+	return update, threads, nil
+}
+
+func (t *telegramClient) update(update gobotapi.Update, threads threadFields) Update {
+	if query := update.CallbackQuery; query != nil {
+		if query.Message == nil || query.Message.Chat == nil {
+			return Update{} // Buttons of inline-mode messages, which the bot does not send
+		}
+		callback := &Callback{
+			ID:        query.ID,
+			ChatID:    query.Message.Chat.ID,
+			MessageID: query.Message.MessageID,
+			Data:      query.Data,
+		}
+		if threads.CallbackQuery != nil {
+			callback.ThreadID = threads.CallbackQuery.Message.threadID()
+		}
+		return Update{Callback: callback}
+	}
+	if cmd, ok := t.command(update); ok {
+		cmd.ThreadID = threads.Message.threadID()
+		return Update{Command: &cmd}
+	}
+	return Update{}
+}
+
+// command extracts the command of an update. In groups, commands explicitly addressed to
+// another bot ("/list@otherbot") are ignored.
+func (t *telegramClient) command(update gobotapi.Update) (Command, bool) {
+	msg := update.Message
+	if msg == nil {
+		msg = update.ChannelPost
+	}
+	if msg == nil || msg.Chat == nil || !msg.IsCommand() {
+		return Command{}, false
+	}
+
+	name, bot, addressed := strings.Cut(msg.CommandWithAt(), "@")
+	if addressed && !strings.EqualFold(bot, t.bot.Self.UserName) {
+		return Command{}, false
+	}
+
+	return Command{
+		ChatID:   msg.Chat.ID,
+		ChatType: msg.Chat.Type,
+		Name:     strings.ToLower(name),
+		Args:     strings.TrimSpace(msg.CommandArguments()),
+	}, true
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+)
+
+// PayloadVersion is bumped on breaking changes to Payload. Additive changes keep the version.
+const PayloadVersion = 1
+
+// Payload is the JSON body POSTed to webhook subscribers.
+type Payload struct {
+	Version      int                  `json:"version"`
+	Event        string               `json:"event"` // Delivery kind, e.g. "release" or "release_updated"
+	DeliveryID   uuid.UUID            `json:"delivery_id"`
+	Timestamp    time.Time            `json:"timestamp"`
+	Repo         *PayloadRepo         `json:"repo"`
+	Release      *PayloadRelease      `json:"release,omitempty"` // Absent for repo-level events
+	Subscription *PayloadSubscription `json:"subscription"`
+	Text         string               `json:"text,omitempty"` // Body rendered with the subscription's template
+}
+
+type PayloadRepo struct {
+	ID       uuid.UUID `json:"id"`
+	Owner    string    `json:"owner"`
+	Name     string    `json:"name"`
+	FullName string    `json:"full_name"`
+	URL      string    `json:"url"`
+	Archived bool      `json:"archived"`
+}
+
+type PayloadRelease struct {
+	ID          uuid.UUID  `json:"id"`
+	Tag         string     `json:"tag"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Body        string     `json:"body"`
+	Type        string     `json:"type"` // major, minor, patch, prerelease or unknown
+	Prerelease  bool       `json:"prerelease"`
+	PublishedAt time.Time  `json:"published_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Changes     string     `json:"changes,omitempty"` // Summary of edited release notes, for "release_updated"
+}
+
+type PayloadSubscription struct {
+	ID      uuid.UUID `json:"id"`
+	UserID  uuid.UUID `json:"user_id"`
+	Channel string    `json:"channel"`
+}
+
+func buildPayload(msg *notify.Message) *Payload {
+	payload := &Payload{
+		Version:    PayloadVersion,
+		Event:      msg.Kind,
+		DeliveryID: msg.Delivery.ID,
+		Timestamp:  time.Now().UTC(),
+		Text:       msg.Text,
+		Subscription: &PayloadSubscription{
+			ID:      msg.Subscription.ID,
+			UserID:  msg.Subscription.UserID,
+			Channel: msg.Subscription.Channel,
+		},
+	}
+
+	if repo := msg.Repo; repo != nil {
+		payload.Repo = &PayloadRepo{
+			ID:       repo.ID,
+			Owner:    repo.Owner,
+			Name:     repo.Name,
+			FullName: repo.Owner + "/" + repo.Name,
+			URL:      "https://github.com/" + repo.Owner + "/" + repo.Name,
+			Archived: repo.Archived,
+		}
+	}
+
+	if release := msg.Release; release != nil {
+		payload.Release = &PayloadRelease{
+			ID:          release.ID,
+			Tag:         release.Tag,
+			Title:       release.Title,
+			URL:         release.URL,
+			Body:        release.Body,
+			Type:        string(release.Type()),
+			Prerelease:  release.Prerelease,
+			PublishedAt: release.PublishedAt,
+			DeletedAt:   release.DeletedAt,
+		}
+		if msg.Revision != nil {
+			payload.Release.Changes = msg.Revision.Summary
+		}
+	}
+	return payload
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// Headers sent with every webhook request.
+const (
+	HeaderDelivery  = "X-ReleaseRadar-Delivery"  // Delivery ID; stable across retries, for deduplication
+	HeaderEvent     = "X-ReleaseRadar-Event"     // Delivery kind, same as the payload's "event"
+	HeaderSignature = "X-ReleaseRadar-Signature" // "sha256=<hex HMAC-SHA256 of the body>"
+)
+
+const (
+	maxAttempts       = 3
+	defaultRetryAfter = 5 * time.Second
+	userAgent         = "ReleaseRadar-Webhook/1"
+)
+
+var errNoSubscription = errors.New("subscription was removed, cannot sign payload")
+
+type notifier struct {
+	httpClient *http.Client
+	lookupIP   notify.LookupIPFunc
+}
+
+// NewNotifier returns a notify.Notifier for "webhook:<URL>" channels. Payloads are signed
+// with the subscription's secret. httpClient should come from notify.NewPublicClient, so that
+// a webhook host cannot be re-pointed at an internal address after it was validated.
+func NewNotifier(httpClient *http.Client) notify.Notifier {
+	return &notifier{httpClient: httpClient, lookupIP: net.DefaultResolver.LookupIPAddr}
+}
+
+func (n *notifier) Validate(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", webhookURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("webhook URL must not contain credentials")
+	}
+
+	// Sending re-checks the address it connects to; this catches the obvious cases up front
+	return notify.CheckPublicHost(u.Hostname(), n.lookupIP)
+}
+
+func (n *notifier) Send(ctx context.Context, webhookURL string, msg *notify.Message) (notify.Result, error) {
+	if msg.Subscription == nil || msg.Subscription.Secret == "" {
+		return notify.Result{}, errNoSubscription
+	}
+
+	payload, err := json.Marshal(buildPayload(msg))
+	if err != nil {
+		return notify.Result{}, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, err := n.post(ctx, webhookURL, msg, payload)
+		if err == nil {
+			return result, nil
+		}
+		if retryAfter == 0 {
+			return result, err
+		}
+		if retryAfter > notify.MaxRetryAfter || attempt == maxAttempts {
+			return result, &notify.RetryAfterError{After: retryAfter, Err: err}
+		}
+
+		logger.L().Sugar().Warnf("webhook returned status %d, retrying in %s", result.StatusCode, retryAfter)
+		if err := notify.Sleep(ctx, retryAfter); err != nil {
+			return result, err
+		}
+	}
+}
+
+// post sends one webhook request. A non-zero duration means the receiver was rate limited
+// or temporarily unavailable and the request should be retried after it.
+func (n *notifier) post(ctx context.Context, webhookURL string, msg *notify.Message, payload []byte) (notify.Result, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderDelivery, msg.Delivery.ID.String())
+	req.Header.Set(HeaderEvent, msg.Kind)
+	req.Header.Set(HeaderSignature, Signature([]byte(msg.Subscription.Secret), payload))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return notify.Result{}, 0, fmt.Errorf("webhook client error: %w", err)
+	}
+	defer resp.Body.Close()
+	result := notify.Result{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return result, notify.RetryAfter(resp.Header, defaultRetryAfter), fmt.Errorf("webhook client error: status %d", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return result, 0, fmt.Errorf("webhook client error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return result, 0, nil
+}
+
+// Signature returns the HeaderSignature value for body. Receivers recompute it over the raw
+// request body with their secret and compare in constant time.
+func Signature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a random signing secret for a webhook subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
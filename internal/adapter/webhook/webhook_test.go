@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+)
+
+// fakeResolver answers lookups from a fixed table instead of DNS.
+func fakeResolver(hosts map[string]string) notify.LookupIPFunc {
+	return func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		addr, ok := hosts[host]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []net.IPAddr{{IP: net.ParseIP(addr)}}, nil
+	}
+}
+
+func testMessage() *notify.Message {
+	return &notify.Message{
+		Kind:         domain.DeliveryKindRelease,
+		Repo:         &domain.Repo{Owner: "golang", Name: "go"},
+		Release:      &domain.Release{Tag: "v1.2.0"},
+		Delivery:     &domain.Delivery{ID: uuid.New()},
+		Subscription: &domain.Subscription{Secret: "secret"},
+	}
+}
+
+func TestValidateRejectsInternalDestinations(t *testing.T) {
+	n := &notifier{lookupIP: fakeResolver(map[string]string{
+		"hooks.example.com":  "93.184.215.14",
+		"localhost":          "127.0.0.1",
+		"rebind.example.com": "10.0.0.5",
+	})}
+
+	for _, u := range []string{
+		"http://127.0.0.1/hook",
+		"http://127.1.2.3:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/hook",
+		"http://172.16.4.2/hook",
+		"https://192.168.1.10/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"https://rebind.example.com/hook",
+	} {
+		if err := n.Validate(u); !errors.Is(err, notify.ErrPrivateAddress) {
+			t.Errorf("Validate(%q) = %v, want ErrPrivateAddress", u, err)
+		}
+	}
+
+	if err := n.Validate("https://unknown.example.com/hook"); err == nil {
+		t.Error("Validate accepted a host that does not resolve")
+	}
+	for _, u := range []string{"https://hooks.example.com/hook", "https://93.184.215.14/hook"} {
+		if err := n.Validate(u); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", u, err)
+		}
+	}
+}
+
+func TestSendRefusesInternalAddressAtDialTime(t *testing.T) {
+	// The host passed validation earlier, but now resolves to loopback
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(notify.NewPublicClient(5*time.Second)).Send(context.Background(), server.URL, testMessage())
+	if !errors.Is(err, notify.ErrPrivateAddress) || !errors.Is(err, notify.ErrPermanent) {
+		t.Fatalf("Send error = %v, want a permanent ErrPrivateAddress", err)
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests, want none", requests)
+	}
+}
+
+func TestSendReturnsLongRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewNotifier(server.Client()).Send(context.Background(), server.URL, testMessage())
+	var retryAfter *notify.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.After != 2*time.Minute {
+		t.Fatalf("Send error = %v, want a *notify.RetryAfterError for 2m", err)
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChannelType identifies the kind of destination a subscription delivers to.
+type ChannelType string
+
+const (
+	ChannelTelegram ChannelType = "telegram"
+	ChannelSlack    ChannelType = "slack"
+	ChannelDiscord  ChannelType = "discord"
+	ChannelEmail    ChannelType = "email"
+	ChannelWebhook  ChannelType = "webhook"
+	ChannelTeams    ChannelType = "teams"
+	ChannelMatrix   ChannelType = "matrix"
+	ChannelNtfy     ChannelType = "ntfy"
+	ChannelGotify   ChannelType = "gotify"
+)
+
+// ChannelTarget is a parsed subscription channel such as "telegram:123456" or
+// "email:dev@example.com". Address is interpreted by the channel type's sender.
+type ChannelTarget struct {
+	Type    ChannelType
+	Address string
+}
+
+// ParseChannel splits a channel string into its type and address. Channels without a type
+// prefix are legacy Telegram chat IDs.
+func ParseChannel(channel string) (ChannelTarget, error) {
+	channel = strings.TrimSpace(channel)
+	if channel == "" {
+		return ChannelTarget{}, fmt.Errorf("empty channel")
+	}
+
+	channelType, address, found := strings.Cut(channel, ":")
+	if !found {
+		return ChannelTarget{Type: ChannelTelegram, Address: channel}, nil
+	}
+	if address == "" {
+		return ChannelTarget{}, fmt.Errorf("channel %q has no address", channel)
+	}
+	return ChannelTarget{Type: ChannelType(strings.ToLower(channelType)), Address: address}, nil
+}
+
+// String returns the canonical "<type>:<address>" form stored on subscriptions and deliveries.
+func (t ChannelTarget) String() string {
+	return string(t.Type) + ":" + t.Address
+}
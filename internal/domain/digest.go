@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Delivery modes of a subscription. Subscriptions in a digest mode have their deliveries
+// collected and sent as one grouped message per user and channel.
+const (
+	DeliveryModeInstant = "instant"
+	DeliveryModeHourly  = "hourly"
+	DeliveryModeDaily   = "daily"  // At DigestTime in the user's timezone
+	DeliveryModeWeekly  = "weekly" // On DigestWeekday at DigestTime in the user's timezone
+)
+
+// DefaultDigestTime is when daily and weekly digests go out unless the subscription says otherwise.
+const DefaultDigestTime = "09:00"
+
+// digestTimeLayout is the "HH:MM" form of DigestTime.
+const digestTimeLayout = "15:04"
+
+// ValidateDeliveryMode checks a delivery mode with its digest time ("HH:MM") and weekday.
+func ValidateDeliveryMode(mode, digestTime string, weekday int) error {
+	switch mode {
+	case DeliveryModeInstant, DeliveryModeHourly:
+		return nil
+	case DeliveryModeDaily, DeliveryModeWeekly:
+	default:
+		return fmt.Errorf("unknown delivery mode %q, want instant, hourly, daily or weekly", mode)
+	}
+	if _, err := time.Parse(digestTimeLayout, digestTime); err != nil {
+		return fmt.Errorf("digest time %q is not HH:MM", digestTime)
+	}
+	if weekday < int(time.Sunday) || weekday > int(time.Saturday) {
+		return fmt.Errorf("digest weekday %d is not between 0 (Sunday) and 6 (Saturday)", weekday)
+	}
+	return nil
+}
+
+// IsDigest reports whether the subscription collects its deliveries into digests.
+func (s *Subscription) IsDigest() bool {
+	return s.DeliveryMode != "" && s.DeliveryMode != DeliveryModeInstant
+}
+
+// NextDigest returns when the next digest of the subscription after now is due, with daily
+// and weekly digests scheduled in loc.
+func (s *Subscription) NextDigest(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	year, month, day := local.Date()
+
+	if s.DeliveryMode == DeliveryModeHourly {
+		return time.Date(year, month, day, local.Hour(), 0, 0, 0, loc).Add(time.Hour)
+	}
+
+	at, err := time.Parse(digestTimeLayout, s.DigestTime)
+	if err != nil {
+		at, _ = time.Parse(digestTimeLayout, DefaultDigestTime)
+	}
+	days := 0
+	step := 1
+	if s.DeliveryMode == DeliveryModeWeekly {
+		days = (s.DigestWeekday - int(local.Weekday()) + 7) % 7
+		step = 7
+	}
+	// time.Date normalises day overflow and keeps the wall-clock time across DST changes
+	next := time.Date(year, month, day+days, at.Hour(), at.Minute(), 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(year, month, day+days+step, at.Hour(), at.Minute(), 0, 0, loc)
+	}
+	return next
+}
+
+// Location returns the user's timezone, UTC if none is set or it is unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
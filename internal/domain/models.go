@@ -7,52 +7,146 @@ import (
 )
 
 type User struct {
+	ID             uuid.UUID `json:"id"`
+	Email          string    `json:"email"`                      // Empty for users created from a Telegram chat
+	TelegramChatID *int64    `json:"telegram_chat_id,omitempty"` // Chat the user was created from with the bot's /start
+	Timezone       string    `json:"timezone"`                   // IANA name, e.g. "Europe/Berlin", for digest schedules; empty is UTC
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type Repo struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	GithubID      int64      `json:"github_id"` // GitHub's numeric repo ID, stable across renames and transfers
+	Owner         string     `json:"owner"`
+	Name          string     `json:"name"`
+	ETag          string     `json:"etag" gorm:"column:etag"`                   // Of the latest release
+	ReleasesETag  string     `json:"releases_etag" gorm:"column:releases_etag"` // Of the release list, see PollerUseCase.syncReleaseHistory
+	Archived      bool       `json:"archived"`
+	NotFoundCount int        `json:"not_found_count"`          // Consecutive polls that got 404 from GitHub
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"` // Set when polling stops for a repo that is gone
+	LastCheckedAt time.Time  `json:"last_checked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type Subscription struct {
+	ID             uuid.UUID  `json:"id"`
+	RepoID         uuid.UUID  `json:"repo_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Channel        string     `json:"channel"`                   // Typed target, e.g. "telegram:<chat>" (see ParseChannel)
+	NotifyOnUpdate bool       `json:"notify_on_update"`          // Notify when release notes are edited
+	NotifyOnDelete bool       `json:"notify_on_delete"`          // Notify when a release is retracted upstream
+	IncludeAssets  bool       `json:"include_assets"`            // List release assets in notifications
+	MajorOnly      bool       `json:"major_only"`                // Only notify about major releases
+	MutedUntil     *time.Time `json:"muted_until,omitempty"`     // No notifications until then
+	RepoTopics     bool       `json:"repo_topics"`               // Telegram forums: post each repo in a topic of its own
+	DeliveryMode   string     `json:"delivery_mode"`             // instant, hourly, daily or weekly (see ValidateDeliveryMode)
+	DigestTime     string     `json:"digest_time"`               // "HH:MM" in the user's timezone, for daily and weekly digests
+	DigestWeekday  int        `json:"digest_weekday"`            // Day of weekly digests, 0 is Sunday
+	Secret         string     `json:"-"`                         // Signs webhook payloads; only set for webhook channels and only returned on creation
+	Template       string     `json:"template"`                  // Custom message template; empty uses the channel default
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`     // Set when the channel stopped accepting messages
+	DisabledReason string     `json:"disabled_reason,omitempty"` // Error that disabled the subscription
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type Release struct {
+	ID          uuid.UUID  `json:"id"`
+	RepoID      uuid.UUID  `json:"repo_id"`
+	Tag         string     `json:"tag"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Body        string     `json:"body"`
+	Prerelease  bool       `json:"prerelease"`
+	Author      string     `json:"author,omitempty"` // GitHub login of the publisher
+	PublishedAt time.Time  `json:"published_at"`
+	Hash        string     `json:"hash"`                 // Hash of release content for idempotency
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // Set when the release disappears upstream
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ReleaseRevision is a snapshot of release content, recorded each time the content changes.
+type ReleaseRevision struct {
 	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
+	ReleaseID uuid.UUID `json:"release_id"`
+	Tag       string    `json:"tag"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Body      string    `json:"body"`
+	Hash      string    `json:"hash"`
+	Summary   string    `json:"summary"` // Human-readable diff against the previous revision
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type Repo struct {
+// ReleaseAsset is a file attached to a release. SHA256 is filled in when the release
+// publishes a checksums file that lists the asset.
+type ReleaseAsset struct {
 	ID            uuid.UUID `json:"id"`
-	UserID        uuid.UUID `json:"user_id"`
-	Owner         string    `json:"owner"`
+	ReleaseID     uuid.UUID `json:"release_id"`
 	Name          string    `json:"name"`
-	ETag          string    `json:"etag"`
-	LastCheckedAt time.Time `json:"last_checked_at"`
+	Size          int64     `json:"size"`
+	ContentType   string    `json:"content_type"`
+	DownloadURL   string    `json:"download_url"`
+	DownloadCount int       `json:"download_count"`
+	SHA256        string    `json:"sha256,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
-type Subscription struct {
-	ID        uuid.UUID `json:"id"`
+// TelegramTopic is the forum topic created in a Telegram chat for a repo's notifications.
+type TelegramTopic struct {
+	ChatID    string    `json:"chat_id"`
 	RepoID    uuid.UUID `json:"repo_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Channel   string    `json:"channel"` // Telegram chat ID or similar
+	ThreadID  int       `json:"thread_id"` // message_thread_id of the topic
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type Release struct {
-	ID          uuid.UUID `json:"id"`
-	RepoID      uuid.UUID `json:"repo_id"`
-	Tag         string    `json:"tag"`
-	Title       string    `json:"title"`
-	URL         string    `json:"url"`
-	PublishedAt time.Time `json:"published_at"`
-	Hash        string    `json:"hash"` // Hash of release content for idempotency
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+// QuietHours is a daily window, in the user's timezone, during which deliveries are held
+// back. Windows may span midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Channel       string    `json:"channel"`        // Empty for the user's default; a channel's own window wins over it
+	Start         string    `json:"start"`          // "HH:MM"
+	End           string    `json:"end"`            // "HH:MM"
+	Bundle        bool      `json:"bundle"`         // Send what was held as one digest when the window ends
+	AllowSecurity bool      `json:"allow_security"` // Security releases are sent during the window
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// Delivery kinds
+const (
+	DeliveryKindRelease         = "release"
+	DeliveryKindReleaseUpdated  = "release_updated"
+	DeliveryKindReleaseDeleted  = "release_deleted"
+	DeliveryKindRepoDeactivated = "repo_deactivated"
+
+	// DeliveryKindDigest is the kind of the message grouping digest deliveries; the
+	// deliveries themselves keep their own kind.
+	DeliveryKindDigest = "digest"
+)
+
 type Delivery struct {
-	ID        uuid.UUID `json:"id"`
-	ReleaseID uuid.UUID `json:"release_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Channel   string    `json:"channel"`
-	Status    string    `json:"status"` // e.g., "pending", "sent", "failed"
-	Attempt   int       `json:"attempt"`
-	LastError string    `json:"last_error"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	RepoID        uuid.UUID  `json:"repo_id"`
+	ReleaseID     *uuid.UUID `json:"release_id,omitempty"`   // Unset for repo-level notices
+	RevisionID    *uuid.UUID `json:"revision_id,omitempty"`  // Set for "release_updated" deliveries
+	RetractedAt   *time.Time `json:"retracted_at,omitempty"` // Set for "release_deleted" deliveries, so each retraction of a release is delivered
+	UserID        uuid.UUID  `json:"user_id"`
+	Channel       string     `json:"channel"`
+	Kind          string     `json:"kind"`   // e.g., "release", "release_updated", "repo_deactivated"
+	Status        string     `json:"status"` // e.g., "pending", "digest", "deferred", "sent", "failed", "failed_permanent", "dead"
+	Attempt       int        `json:"attempt"`
+	LastError     string     `json:"last_error"`
+	ResponseCode  int        `json:"response_code,omitempty"`   // HTTP status of the latest attempt, for HTTP-based channels
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty"`    // When a "digest" delivery's digest is due, or a "deferred" delivery's quiet hours end
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // When a "failed" delivery is retried
+	ClaimedBy     string     `json:"claimed_by,omitempty"`      // Worker sending the delivery, until ClaimedUntil
+	ClaimedUntil  *time.Time `json:"claimed_until,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateQuietHours checks the start and end ("HH:MM") of a quiet-hours window.
+func ValidateQuietHours(start, end string) error {
+	for _, t := range []string{start, end} {
+		if _, err := time.Parse(digestTimeLayout, t); err != nil {
+			return fmt.Errorf("quiet hours time %q is not HH:MM", t)
+		}
+	}
+	if start == end {
+		return fmt.Errorf("quiet hours start and end at the same time")
+	}
+	return nil
+}
+
+// Until returns the end of the window if now falls inside it, with the window in loc.
+func (q *QuietHours) Until(now time.Time, loc *time.Location) (time.Time, bool) {
+	start, err := time.Parse(digestTimeLayout, q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(digestTimeLayout, q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	year, month, day := local.Date()
+	startToday := time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, loc)
+	endToday := time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, loc)
+
+	switch {
+	case startToday.Equal(endToday):
+		// Empty window
+	case startToday.Before(endToday):
+		// Same-day window, e.g. 12:00 to 14:00
+		if !now.Before(startToday) && now.Before(endToday) {
+			return endToday, true
+		}
+	case now.Before(endToday):
+		// Overnight window that started yesterday
+		return endToday, true
+	case !now.Before(startToday):
+		// Overnight window that ends tomorrow
+		return time.Date(year, month, day+1, end.Hour(), end.Minute(), 0, 0, loc), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// ReleaseType classifies a release by the semantic-version component it bumps.
+type ReleaseType string
+
+const (
+	ReleaseTypeMajor      ReleaseType = "major"
+	ReleaseTypeMinor      ReleaseType = "minor"
+	ReleaseTypePatch      ReleaseType = "patch"
+	ReleaseTypePrerelease ReleaseType = "prerelease"
+	ReleaseTypeUnknown    ReleaseType = "unknown" // Tag is not a semantic version
+)
+
+// semverPattern matches tags like "v1.2.3", "1.2", "release-2.0.0-rc.1".
+var semverPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?(-[0-9A-Za-z.-]+)?`)
+
+// Type derives the release type from the GitHub prerelease flag and the tag. A version with
+// zero minor and patch components is a major release, one with zero patch a minor release.
+func (r *Release) Type() ReleaseType {
+	if r.Prerelease {
+		return ReleaseTypePrerelease
+	}
+
+	m := semverPattern.FindStringSubmatch(r.Tag)
+	if m == nil {
+		return ReleaseTypeUnknown
+	}
+	if m[4] != "" {
+		return ReleaseTypePrerelease
+	}
+
+	minor, _ := strconv.Atoi(m[2])
+	patch := 0
+	if m[3] != "" {
+		patch, _ = strconv.Atoi(m[3])
+	}
+	switch {
+	case minor == 0 && patch == 0:
+		return ReleaseTypeMajor
+	case patch == 0:
+		return ReleaseTypeMinor
+	default:
+		return ReleaseTypePatch
+	}
+}
+
+var (
+	// securityTitlePattern matches tags and titles of security releases, e.g. "v1.2.4 (security fix)".
+	securityTitlePattern = regexp.MustCompile(`(?i)\bsecurity\b|\bCVE-\d{4}-\d{4,}\b|\bGHSA(-[0-9a-z]{4}){3}\b`)
+	// advisoryPattern matches CVE and GitHub security advisory IDs in release notes.
+	advisoryPattern = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b|\bGHSA(-[0-9a-z]{4}){3}\b`)
+)
+
+// IsSecurity reports whether the release is tagged as a security release: its tag or title
+// mentions security or an advisory, or its notes reference a CVE or GitHub security advisory.
+func (r *Release) IsSecurity() bool {
+	return securityTitlePattern.MatchString(r.Tag) || securityTitlePattern.MatchString(r.Title) || advisoryPattern.MatchString(r.Body)
+}
+
+// SemverDiff reports which version component changed between two tags, e.g. "minor" from
+// v1.2.3 to v1.3.0. It returns ReleaseTypeUnknown when either tag is not a semantic version.
+func SemverDiff(from, to string) ReleaseType {
+	a, b := semverPattern.FindStringSubmatch(from), semverPattern.FindStringSubmatch(to)
+	if a == nil || b == nil {
+		return ReleaseTypeUnknown
+	}
+	switch {
+	case component(a[1]) != component(b[1]):
+		return ReleaseTypeMajor
+	case component(a[2]) != component(b[2]):
+		return ReleaseTypeMinor
+	case component(a[3]) != component(b[3]):
+		return ReleaseTypePatch
+	case a[4] != b[4]:
+		return ReleaseTypePrerelease
+	}
+	return ReleaseTypeUnknown
+}
+
+// component normalises a missing patch component to "0", so that 1.2 and 1.2.0 compare equal.
+func component(s string) string {
+	if s == "" {
+		return "0"
+	}
+	n, _ := strconv.Atoi(s)
+	return strconv.Itoa(n)
+}
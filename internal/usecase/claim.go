@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ClaimPolicy says how the notifier claims deliveries, so that several workers can send
+// side by side without sending anything twice. Zero fields take the value of DefaultClaimPolicy.
+type ClaimPolicy struct {
+	WorkerID  string        // Recorded as the claim owner; defaults to DefaultWorkerID()
+	BatchSize int           // Deliveries claimed at a time
+	Lease     time.Duration // How long a claim holds before another worker may take it over
+}
+
+// DefaultClaimPolicy claims 100 deliveries at a time for 10 minutes.
+var DefaultClaimPolicy = ClaimPolicy{BatchSize: 100, Lease: 10 * time.Minute}
+
+// DefaultWorkerID identifies this process among workers: "<hostname>-<pid>".
+func DefaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// withDefaults fills in the zero fields of p.
+func (p ClaimPolicy) withDefaults() ClaimPolicy {
+	if p.WorkerID == "" {
+		p.WorkerID = DefaultWorkerID()
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = DefaultClaimPolicy.BatchSize
+	}
+	if p.Lease <= 0 {
+		p.Lease = DefaultClaimPolicy.Lease
+	}
+	return p
+}
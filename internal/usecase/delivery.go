@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// maxDeadDeliveries caps how many dead deliveries are listed at once.
+const maxDeadDeliveries = 500
+
+type deliveryUseCase struct {
+	deliveryStore persistence.DeliveryRepository
+}
+
+func NewDeliveryUseCase(deliveryStore persistence.DeliveryRepository) DeliveryUseCase {
+	return &deliveryUseCase{deliveryStore: deliveryStore}
+}
+
+// ListDeadDeliveries returns the most recent deliveries that ran out of attempts, of one user
+// if userID is set. limit is capped at 500; zero or less means the cap.
+func (d *deliveryUseCase) ListDeadDeliveries(ctx context.Context, userID *uuid.UUID, limit int) ([]domain.Delivery, error) {
+	const op = "DeliveryUseCase.ListDeadDeliveries"
+	logger.L().Sugar().Debugf("%s: attempting to list dead deliveries", op)
+
+	if limit <= 0 || limit > maxDeadDeliveries {
+		limit = maxDeadDeliveries
+	}
+	deliveries, err := d.deliveryStore.ListDeliveriesByStatus(ctx, "dead", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list dead deliveries: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery requeues a dead or permanently failed delivery with a fresh set of attempts.
+// Other deliveries are rejected with ErrInvalidArgument, as they are sent or retried already.
+func (d *deliveryUseCase) RetryDelivery(ctx context.Context, deliveryID uuid.UUID) (*domain.Delivery, error) {
+	const op = "DeliveryUseCase.RetryDelivery"
+	logger.L().Sugar().Debugf("%s: attempting to retry delivery %s", op, deliveryID)
+
+	delivery, err := d.deliveryStore.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get delivery %s: %w", op, deliveryID, err)
+	}
+	if delivery == nil {
+		return nil, fmt.Errorf("%s: delivery %s: %w", op, deliveryID, persistence.ErrNotFound)
+	}
+	if delivery.Status != "dead" && delivery.Status != "failed_permanent" {
+		return nil, fmt.Errorf("%s: delivery %s is %s: %w", op, deliveryID, delivery.Status, ErrInvalidArgument)
+	}
+
+	if err := d.deliveryStore.RequeueDelivery(ctx, deliveryID); err != nil {
+		return nil, fmt.Errorf("%s: failed to requeue delivery %s: %w", op, deliveryID, err)
+	}
+	delivery.Status = "pending"
+	delivery.Attempt = 0
+	delivery.NextAttemptAt = nil
+
+	logger.L().Sugar().Infof("%s: requeued delivery %s", op, deliveryID)
+	return delivery, nil
+}
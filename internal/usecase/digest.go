@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// digestKey identifies the digest a delivery goes into: one per user and channel.
+type digestKey struct {
+	userID  uuid.UUID
+	channel string
+}
+
+// SendDigests sends the digests that are due. The waiting deliveries of each user and channel
+// go out together as one message listing them, oldest first.
+func (n *notifierUseCase) SendDigests(ctx context.Context) error {
+	const op = "NotifierUseCase.SendDigests"
+	logger.L().Sugar().Debugf("%s: starting digest cycle", op)
+
+	deliveries, err := n.deliveryStore.ListDueDeliveries(ctx, "digest", time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: failed to list due digest deliveries: %w", op, err)
+	}
+
+	if len(deliveries) == 0 {
+		logger.L().Sugar().Debugf("%s: no digests due", op)
+		return nil
+	}
+
+	logger.L().Sugar().Infof("%s: found %d due digest deliveries", op, len(deliveries))
+
+	repos, err := n.loadRepos(ctx, deliveries)
+	if err != nil {
+		return fmt.Errorf("%s: failed to load repos: %w", op, err)
+	}
+
+	groups := make(map[digestKey][]domain.Delivery)
+	var keys []digestKey
+	for _, delivery := range deliveries {
+		key := digestKey{userID: delivery.UserID, channel: delivery.Channel}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], delivery)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		// A digest is identified by its oldest delivery, which no other digest can contain
+		idempotencyKey := fmt.Sprintf("digest:%s:%d", group[0].ID, group[0].Attempt)
+
+		err := n.idempotencyManager.Do(ctx, idempotencyKey, 10*time.Minute, func() error {
+			return n.sendDigest(ctx, key.channel, group, repos)
+		})
+		if err != nil {
+			logger.L().Sugar().Errorf("%s: failed to send digest to user %s on channel %s: %v", op, key.userID, key.channel, err)
+		}
+	}
+
+	logger.L().Sugar().Debugf("%s: finished digest cycle", op)
+	return nil
+}
+
+// sendDigest renders the deliveries of one user and channel as a digest and sends it.
+// Deliveries whose repo or release no longer exists are skipped.
+func (n *notifierUseCase) sendDigest(ctx context.Context, channel string, group []domain.Delivery, repos map[uuid.UUID]*domain.Repo) error {
+	const op = "NotifierUseCase.sendDigest"
+
+	var entries []*notify.Message
+	var included []domain.Delivery
+	for i := range group {
+		delivery := &group[i]
+
+		repo := repos[delivery.RepoID]
+		if repo == nil {
+			logger.L().Sugar().Warnf("%s: repo %s not found for delivery %s, skipping", op, delivery.RepoID, delivery.ID)
+			if err := n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, delivery.ClaimedBy, "skipped", "repo not found", delivery.Attempt+1); err != nil {
+				return fmt.Errorf("%s: failed to skip delivery %s: %w", op, delivery.ID, err)
+			}
+			continue
+		}
+		entry := &notify.Message{Kind: delivery.Kind, Repo: repo, Delivery: delivery}
+
+		if delivery.ReleaseID != nil {
+			release, err := n.releaseStore.GetReleaseByID(ctx, *delivery.ReleaseID)
+			if err != nil {
+				return fmt.Errorf("%s: failed to get release %s for delivery %s: %w", op, *delivery.ReleaseID, delivery.ID, err)
+			}
+			if release == nil {
+				logger.L().Sugar().Warnf("%s: release %s not found for delivery %s, skipping", op, *delivery.ReleaseID, delivery.ID)
+				if err := n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, delivery.ClaimedBy, "skipped", "release not found", delivery.Attempt+1); err != nil {
+					return fmt.Errorf("%s: failed to skip delivery %s: %w", op, delivery.ID, err)
+				}
+				continue
+			}
+			entry.Release = release
+		}
+		if delivery.RevisionID != nil {
+			revision, err := n.releaseStore.GetReleaseRevisionByID(ctx, *delivery.RevisionID)
+			if err != nil {
+				return fmt.Errorf("%s: failed to get revision %s for delivery %s: %w", op, *delivery.RevisionID, delivery.ID, err)
+			}
+			entry.Revision = revision
+		}
+
+		entries = append(entries, entry)
+		included = append(included, *delivery)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// A digest that falls in quiet hours waits for them to end
+	user, err := n.userStore.GetUserByID(ctx, included[0].UserID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get user %s: %w", op, included[0].UserID, err)
+	}
+	if user != nil {
+		until, err := n.quietUntil(ctx, user, channel, nil)
+		if err != nil {
+			return fmt.Errorf("%s: failed to check quiet hours: %w", op, err)
+		}
+		if !until.IsZero() {
+			ids := make([]uuid.UUID, len(included))
+			for i := range included {
+				ids[i] = included[i].ID
+			}
+			logger.L().Sugar().Infof("%s: quiet hours for user %s on channel %s, holding digest until %s", op, user.ID, channel, until)
+			return n.deliveryStore.ScheduleDeliveries(ctx, ids, "digest", &until)
+		}
+	}
+
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", op, notify.ErrInvalidChannel, err)
+	}
+	msg := &notify.Message{
+		Kind:     domain.DeliveryKindDigest,
+		Text:     notify.RenderDigest(target.Type, entries),
+		Delivery: &included[0],
+		Digest:   entries,
+	}
+
+	logger.L().Sugar().Infof("%s: sending digest of %d notifications to user %s on channel %s", op, len(entries), included[0].UserID, channel)
+	return n.deliver(ctx, included, msg)
+}
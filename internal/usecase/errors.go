@@ -0,0 +1,11 @@
+package usecase
+
+import "errors"
+
+// ErrAlreadyExists is returned when creating something that is already there, such as a
+// second subscription to the same repo and channel.
+var ErrAlreadyExists = errors.New("already exists")
+
+// ErrInvalidArgument is returned for settings that are malformed or do not apply, such as an
+// unknown timezone.
+var ErrInvalidArgument = errors.New("invalid argument")
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mackb/releaseradar/internal/adapter/notify"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// messageBuilder assembles notify.Messages and renders them with the subscription's template.
+// The notifier and the template preview share it, so previews match what gets delivered.
+type messageBuilder struct {
+	releaseStore persistence.ReleaseRepository
+
+	// templates caches parsed templates by channel type and source
+	templates sync.Map
+}
+
+func newMessageBuilder(releaseStore persistence.ReleaseRepository) *messageBuilder {
+	return &messageBuilder{releaseStore: releaseStore}
+}
+
+// build loads what the message needs beyond its arguments and renders msg.Text. release and
+// revision may be nil; sub is nil if the subscription was removed.
+func (b *messageBuilder) build(ctx context.Context, kind, channel string, repo *domain.Repo, release *domain.Release, revision *domain.ReleaseRevision, sub *domain.Subscription) (*notify.Message, error) {
+	msg := &notify.Message{Kind: kind, Repo: repo, Release: release, Revision: revision, Subscription: sub}
+
+	if release != nil {
+		previous, err := b.releaseStore.GetPreviousRelease(ctx, release.RepoID, release.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get release preceding %s: %w", release.ID, err)
+		}
+		msg.Previous = previous
+	}
+	if kind == domain.DeliveryKindRelease && sub != nil && sub.IncludeAssets {
+		assets, err := b.releaseStore.ListReleaseAssets(ctx, release.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list assets for release %s: %w", release.ID, err)
+		}
+		msg.Assets = assets
+	}
+
+	custom := ""
+	if sub != nil {
+		custom = sub.Template
+	}
+	text, err := b.render(msg, channel, custom)
+	if err != nil {
+		return nil, err
+	}
+	msg.Text = text
+	return msg, nil
+}
+
+// render executes the custom template, or the channel type's default when custom is empty.
+// Stored templates were validated when saved; should one still fail, the default is used.
+func (b *messageBuilder) render(msg *notify.Message, channel, custom string) (string, error) {
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", notify.ErrInvalidChannel, err)
+	}
+
+	if custom != "" {
+		tmpl, err := b.template(target.Type, custom)
+		if err == nil {
+			var text string
+			if text, err = tmpl.Render(msg); err == nil {
+				return text, nil
+			}
+		}
+		logger.L().Sugar().Warnf("custom template for channel %s failed, using the default: %v", channel, err)
+	}
+
+	tmpl, err := b.template(target.Type, notify.DefaultTemplate(target.Type))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse default template for %s: %w", target.Type, err)
+	}
+	return tmpl.Render(msg)
+}
+
+func (b *messageBuilder) template(channelType domain.ChannelType, text string) (*notify.Template, error) {
+	key := string(channelType) + "\x00" + text
+	if cached, ok := b.templates.Load(key); ok {
+		return cached.(*notify.Template), nil
+	}
+	tmpl, err := notify.ParseTemplate(channelType, text)
+	if err != nil {
+		return nil, err
+	}
+	b.templates.Store(key, tmpl)
+	return tmpl, nil
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// deadDeliveries counts the deliveries that ran out of attempts.
+	deadDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "releaseradar_deliveries_dead_total",
+		Help: "Deliveries dead-lettered after running out of send attempts, by channel type.",
+	}, []string{"channel_type"})
+
+	// sentDeliveries counts send attempts by outcome.
+	sentDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "releaseradar_delivery_attempts_total",
+		Help: "Delivery send attempts by channel type and resulting status.",
+	}, []string{"channel_type", "status"})
+
+	notifierWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "releaseradar_notifier_workers",
+		Help: "Configured size of the notifier's sending pool.",
+	})
+	busyWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "releaseradar_notifier_busy_workers",
+		Help: "Notifier workers currently sending.",
+	})
+)
+
+// channelTypeLabel is the channel type of a delivery channel for metric labels.
+func channelTypeLabel(channel string) string {
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return "unknown"
+	}
+	return string(target.Type)
+}
@@ -2,11 +2,15 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
 	"github.com/mackb/releaseradar/internal/adapter/persistence"
-	"github.com/mackb/releaseradar/internal/adapter/telegram"
+	"github.com/mackb/releaseradar/internal/domain"
 	"github.com/mackb/releaseradar/pkg/idempotency"
 	"github.com/mackb/releaseradar/pkg/logger"
 )
@@ -14,20 +18,41 @@ import (
 type notifierUseCase struct {
 	deliveryStore      persistence.DeliveryRepository
 	releaseStore       persistence.ReleaseRepository
+	repoStore          persistence.RepoRepository
+	subStore           persistence.SubscriptionRepository
 	userStore          persistence.UserRepository
-	telegramClient     telegram.Client
+	quietStore         persistence.QuietHoursRepository
+	channels           *notify.Registry
+	messages           *messageBuilder
 	idempotencyManager *idempotency.Manager
 	transactor         persistence.Transactor
+	retry              RetryPolicy
+	claims             ClaimPolicy
+	concurrency        int
 }
 
-func NewNotifierUseCase(deliveryStore persistence.DeliveryRepository, releaseStore persistence.ReleaseRepository, userStore persistence.UserRepository, telegramClient telegram.Client, idempotencyManager *idempotency.Manager, transactor persistence.Transactor) NotifierUseCase {
+// DefaultNotifierConcurrency is how many channels the notifier sends to at once.
+const DefaultNotifierConcurrency = 8
+
+func NewNotifierUseCase(deliveryStore persistence.DeliveryRepository, releaseStore persistence.ReleaseRepository, repoStore persistence.RepoRepository, subStore persistence.SubscriptionRepository, userStore persistence.UserRepository, quietStore persistence.QuietHoursRepository, channels *notify.Registry, idempotencyManager *idempotency.Manager, transactor persistence.Transactor, retry RetryPolicy, claims ClaimPolicy, concurrency int) NotifierUseCase {
+	if concurrency <= 0 {
+		concurrency = DefaultNotifierConcurrency
+	}
+	notifierWorkers.Set(float64(concurrency))
 	return &notifierUseCase{
 		deliveryStore:      deliveryStore,
 		releaseStore:       releaseStore,
+		repoStore:          repoStore,
+		subStore:           subStore,
 		userStore:          userStore,
-		telegramClient:     telegramClient,
+		quietStore:         quietStore,
+		channels:           channels,
+		messages:           newMessageBuilder(releaseStore),
 		idempotencyManager: idempotencyManager,
 		transactor:         transactor,
+		retry:              retry.withDefaults(),
+		claims:             claims.withDefaults(),
+		concurrency:        concurrency,
 	}
 }
 
@@ -35,62 +60,328 @@ func (n *notifierUseCase) Notify(ctx context.Context) error {
 	const op = "NotifierUseCase.Notify"
 	logger.L().Sugar().Debugf("%s: starting notification cycle", op)
 
-	deliveries, err := n.deliveryStore.ListPendingDeliveries(ctx)
+	// Claim batches until none is left; other workers claim and send theirs side by side
+	for {
+		deliveries, err := n.deliveryStore.ClaimDeliveries(ctx, n.claims.WorkerID, n.claims.BatchSize, n.claims.Lease)
+		if err != nil {
+			return fmt.Errorf("%s: failed to claim pending deliveries: %w", op, err)
+		}
+
+		if len(deliveries) == 0 {
+			logger.L().Sugar().Debugf("%s: no pending deliveries", op)
+			break
+		}
+
+		logger.L().Sugar().Infof("%s: claimed %d pending deliveries as %s", op, len(deliveries), n.claims.WorkerID)
+		if err := n.notifyBatch(ctx, deliveries); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if len(deliveries) < n.claims.BatchSize {
+			break
+		}
+	}
+
+	logger.L().Sugar().Debugf("%s: finished notification cycle", op)
+	return nil
+}
+
+// notifyBatch sends a batch of claimed deliveries. A delivery that fails for reasons other than
+// sending keeps its claim, and is picked up again once the claim expires.
+func (n *notifierUseCase) notifyBatch(ctx context.Context, deliveries []domain.Delivery) error {
+	const op = "NotifierUseCase.notifyBatch"
+
+	// Repos are shared by many deliveries, so load them once for the whole batch
+	repos, err := n.loadRepos(ctx, deliveries)
 	if err != nil {
-		return fmt.Errorf("%s: failed to list pending deliveries: %w", op, err)
+		return fmt.Errorf("%s: failed to load repos: %w", op, err)
 	}
 
-	if len(deliveries) == 0 {
-		logger.L().Sugar().Debugf("%s: no pending deliveries", op)
-		return nil
+	// Deliveries to one channel are sent in order by one worker, and channels are spread over
+	// the pool; the registry's rate limits keep the pool within what each service accepts
+	var channels []string
+	byChannel := make(map[string][]domain.Delivery)
+	for _, delivery := range deliveries {
+		if _, ok := byChannel[delivery.Channel]; !ok {
+			channels = append(channels, delivery.Channel)
+		}
+		byChannel[delivery.Channel] = append(byChannel[delivery.Channel], delivery)
 	}
 
-	logger.L().Sugar().Infof("%s: found %d pending deliveries", op, len(deliveries))
+	queue := make(chan []domain.Delivery)
+	var wg sync.WaitGroup
+	for i := 0; i < min(n.concurrency, len(channels)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range queue {
+				busyWorkers.Inc()
+				for _, delivery := range group {
+					n.notifyOne(ctx, delivery, repos)
+				}
+				busyWorkers.Dec()
+			}
+		}()
+	}
+	for _, channel := range channels {
+		queue <- byChannel[channel]
+	}
+	close(queue)
+	wg.Wait()
+	return nil
+}
 
-	for _, delivery := range deliveries {
-		// Use idempotency manager to ensure delivery is processed only once
-		idempotencyKey := fmt.Sprintf("notify:%s:%s:%s", delivery.ReleaseID, delivery.UserID, delivery.Channel)
+// notifyOne sends a claimed delivery, logging rather than returning what goes wrong.
+func (n *notifierUseCase) notifyOne(ctx context.Context, delivery domain.Delivery, repos map[uuid.UUID]*domain.Repo) {
+	const op = "NotifierUseCase.notifyOne"
+
+	// Use idempotency manager to ensure each attempt of a delivery is processed only once
+	idempotencyKey := fmt.Sprintf("notify:%s:%d", delivery.ID, delivery.Attempt)
 
-		err := n.idempotencyManager.Do(ctx, idempotencyKey, 10*time.Minute, func() error {
-			// Fetch associated release and user details
-			release, err := n.releaseStore.GetReleaseByID(ctx, delivery.ReleaseID)
+	err := n.idempotencyManager.Do(ctx, idempotencyKey, 10*time.Minute, func() error {
+		// Fetch associated release and user details; repo-level notices have no release
+		var release *domain.Release
+		if delivery.ReleaseID != nil {
+			var err error
+			release, err = n.releaseStore.GetReleaseByID(ctx, *delivery.ReleaseID)
 			if err != nil {
-				return fmt.Errorf("%s: failed to get release %s for delivery %s: %w", op, delivery.ReleaseID, delivery.ID, err)
+				return fmt.Errorf("%s: failed to get release %s for delivery %s: %w", op, *delivery.ReleaseID, delivery.ID, err)
 			}
 			if release == nil {
-				logger.L().Sugar().Warnf("%s: release %s not found for delivery %s, skipping", op, delivery.ReleaseID, delivery.ID)
-				return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, "skipped", "release not found", delivery.Attempt+1) // Update status to skipped
+				logger.L().Sugar().Warnf("%s: release %s not found for delivery %s, skipping", op, *delivery.ReleaseID, delivery.ID)
+				return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, delivery.ClaimedBy, "skipped", "release not found", delivery.Attempt+1) // Update status to skipped
 			}
+		}
 
-			user, err := n.userStore.GetUserByID(ctx, delivery.UserID)
+		user, err := n.userStore.GetUserByID(ctx, delivery.UserID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get user %s for delivery %s: %w", op, delivery.UserID, delivery.ID, err)
+		}
+		if user == nil {
+			logger.L().Sugar().Warnf("%s: user %s not found for delivery %s, skipping", op, delivery.UserID, delivery.ID)
+			return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, delivery.ClaimedBy, "skipped", "user not found", delivery.Attempt+1) // Update status to skipped
+		}
+
+		// The subscription may be gone by now; it only carries display preferences
+		sub, err := n.subStore.GetSubscription(ctx, delivery.RepoID, delivery.UserID, delivery.Channel)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get subscription for delivery %s: %w", op, delivery.ID, err)
+		}
+
+		repo := repos[delivery.RepoID]
+		if repo == nil {
+			logger.L().Sugar().Warnf("%s: repo %s not found for delivery %s, skipping", op, delivery.RepoID, delivery.ID)
+			return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, delivery.ClaimedBy, "skipped", "repo not found", delivery.Attempt+1) // Update status to skipped
+		}
+
+		// Hold the delivery until the user's quiet hours end
+		until, err := n.quietUntil(ctx, user, delivery.Channel, release)
+		if err != nil {
+			return fmt.Errorf("%s: failed to check quiet hours for delivery %s: %w", op, delivery.ID, err)
+		}
+		if !until.IsZero() {
+			logger.L().Sugar().Infof("%s: quiet hours for user %s on channel %s, deferring delivery %s until %s", op, user.ID, delivery.Channel, delivery.ID, until)
+			return n.deliveryStore.ScheduleDeliveries(ctx, []uuid.UUID{delivery.ID}, "deferred", &until)
+		}
+
+		var revision *domain.ReleaseRevision
+		if delivery.RevisionID != nil {
+			revision, err = n.releaseStore.GetReleaseRevisionByID(ctx, *delivery.RevisionID)
 			if err != nil {
-				return fmt.Errorf("%s: failed to get user %s for delivery %s: %w", op, delivery.UserID, delivery.ID, err)
+				return fmt.Errorf("%s: failed to get revision %s for delivery %s: %w", op, *delivery.RevisionID, delivery.ID, err)
 			}
-			if user == nil {
-				logger.L().Sugar().Warnf("%s: user %s not found for delivery %s, skipping", op, delivery.UserID, delivery.ID)
-				return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, "skipped", "user not found", delivery.Attempt+1) // Update status to skipped
+		}
+
+		msg, err := n.messages.build(ctx, delivery.Kind, delivery.Channel, repo, release, revision, sub)
+		if err != nil {
+			return fmt.Errorf("%s: failed to build message for delivery %s: %w", op, delivery.ID, err)
+		}
+		msg.Delivery = &delivery
+
+		logger.L().Sugar().Infof("%s: sending %s message for delivery %s to user %s on channel %s", op, delivery.Kind, delivery.ID, user.ID, delivery.Channel)
+		return n.deliver(ctx, []domain.Delivery{delivery}, msg)
+	})
+
+	if errors.Is(err, persistence.ErrClaimLost) {
+		logger.L().Sugar().Warnf("%s: delivery %s was claimed by another worker, leaving it to them", op, delivery.ID)
+	} else if err != nil {
+		logger.L().Sugar().Errorf("%s: failed to process delivery %s: %v", op, delivery.ID, err)
+	}
+}
+
+// deliver sends msg to the channel of deliveries, which all share it, and records the outcome
+// on each of them. If the destination moved, e.g. a Telegram group became a supergroup, the
+// user's subscriptions follow it and the message is resent once. Failed deliveries are retried
+// with backoff until they run out of attempts; a failed digest is retried as a digest. The
+// returned error is for failures other than sending, which leave the deliveries as they were.
+func (n *notifierUseCase) deliver(ctx context.Context, deliveries []domain.Delivery, msg *notify.Message) error {
+	const op = "NotifierUseCase.deliver"
+	delivery := deliveries[0]
+
+	result, sendErr := n.channels.Send(ctx, &delivery, msg)
+
+	var moved *notify.MovedError
+	if errors.As(sendErr, &moved) {
+		target, err := n.followMove(ctx, delivery.UserID, delivery.Channel, moved.Address)
+		if err != nil {
+			return fmt.Errorf("%s: failed to follow moved channel for delivery %s: %w", op, delivery.ID, err)
+		}
+		logger.L().Sugar().Infof("%s: channel %s moved to %s, resending delivery %s", op, delivery.Channel, target, delivery.ID)
+		movedDelivery := delivery
+		movedDelivery.Channel = target
+		result, sendErr = n.channels.Send(ctx, &movedDelivery, msg)
+	}
+
+	// The destination may have asked for a pause longer than the backoff of the next attempt
+	var retryAfter *notify.RetryAfterError
+	errors.As(sendErr, &retryAfter)
+
+	now := time.Now()
+	status, lastError := "sent", ""
+	if sendErr != nil {
+		logger.L().Sugar().Errorf("%s: failed to send message for delivery %s: %v", op, delivery.ID, sendErr)
+		// Mark as failed and retry later, unless retrying cannot help
+		status, lastError = "failed", sendErr.Error()
+		if errors.Is(sendErr, notify.ErrPermanent) {
+			status = "failed_permanent"
+		}
+		if errors.Is(sendErr, notify.ErrGone) {
+			if err := n.disableChannel(ctx, delivery.UserID, delivery.Channel, sendErr.Error()); err != nil {
+				return fmt.Errorf("%s: failed to disable subscriptions on channel %s: %w", op, delivery.Channel, err)
 			}
+		}
+	} else {
+		logger.L().Sugar().Infof("%s: successfully sent message for delivery %s", op, delivery.ID)
+	}
 
-			message := fmt.Sprintf("New release for %s/%s: <b>%s</b> (%s)\n%s", release.RepoID, "", release.Title, release.Tag, release.URL) // Placeholder for repo name
-			// In a real scenario, you'd get repo details from release.RepoID to display owner/name
+	for _, d := range deliveries {
+		status, nextAttemptAt := status, (*time.Time)(nil)
+		if status == "failed" {
+			status, nextAttemptAt = n.retry.next(d.Attempt+1, now)
+			if retryAfter != nil && nextAttemptAt != nil && nextAttemptAt.Before(now.Add(retryAfter.After)) {
+				at := now.Add(retryAfter.After)
+				nextAttemptAt = &at
+			}
+		}
+		// A worker that took over the delivery after this one's lease expired owns its outcome
+		err := n.deliveryStore.RecordDeliveryAttempt(ctx, d.ID, d.ClaimedBy, status, lastError, d.Attempt+1, result.StatusCode, nextAttemptAt)
+		if errors.Is(err, persistence.ErrClaimLost) {
+			logger.L().Sugar().Warnf("%s: delivery %s was claimed by another worker, not recording its attempt", op, d.ID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: failed to record attempt of delivery %s: %w", op, d.ID, err)
+		}
 
-			logger.L().Sugar().Infof("%s: sending message for release %s to user %s on channel %s", op, release.ID, user.ID, delivery.Channel)
-			sendErr := n.telegramClient.SendMessage(ctx, delivery.Channel, message)
-			if sendErr != nil {
-				logger.L().Sugar().Errorf("%s: failed to send telegram message for delivery %s: %v", op, delivery.ID, sendErr)
-				// Mark as failed and retry later
-				return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, "failed", sendErr.Error(), delivery.Attempt+1)
+		sentDeliveries.WithLabelValues(channelTypeLabel(d.Channel), status).Inc()
+
+		switch {
+		case status == "dead":
+			logger.L().Sugar().Warnf("%s: delivery %s is dead after %d attempts: %s", op, d.ID, d.Attempt+1, lastError)
+			deadDeliveries.WithLabelValues(channelTypeLabel(d.Channel)).Inc()
+		case status == "failed" && msg.Kind == domain.DeliveryKindDigest:
+			// Retry the digest as a whole rather than its entries one by one
+			if err := n.deliveryStore.ScheduleDeliveries(ctx, []uuid.UUID{d.ID}, "digest", nextAttemptAt); err != nil {
+				return fmt.Errorf("%s: failed to reschedule digest delivery %s: %w", op, d.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// disableChannel disables the user's subscriptions on a channel that no longer accepts
+// messages, so no further deliveries are enqueued for it.
+func (n *notifierUseCase) disableChannel(ctx context.Context, userID uuid.UUID, channel, reason string) error {
+	const op = "NotifierUseCase.disableChannel"
+
+	return n.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		subs, err := n.subStore.ListSubscriptionsByUserID(txCtx, userID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to list subscriptions of user %s: %w", op, userID, err)
+		}
+
+		now := time.Now()
+		for i := range subs {
+			sub := &subs[i]
+			if sub.Channel != channel || sub.DisabledAt != nil {
+				continue
+			}
+			sub.DisabledAt = &now
+			sub.DisabledReason = reason
+			sub.UpdatedAt = now
+			if err := n.subStore.UpdateSubscription(txCtx, sub); err != nil {
+				return fmt.Errorf("%s: failed to disable subscription %s: %w", op, sub.ID, err)
 			}
+			logger.L().Sugar().Infof("%s: disabled subscription %s on channel %s: %s", op, sub.ID, channel, reason)
+		}
+		return nil
+	})
+}
 
-			logger.L().Sugar().Infof("%s: successfully sent telegram message for delivery %s", op, delivery.ID)
-			return n.deliveryStore.UpdateDeliveryStatus(ctx, delivery.ID, "sent", "", delivery.Attempt+1)
-		})
+// followMove points the user's subscriptions on channel at the address it moved to, and returns
+// the new channel. A subscription that already exists on the new channel wins over the old one.
+func (n *notifierUseCase) followMove(ctx context.Context, userID uuid.UUID, channel, address string) (string, error) {
+	const op = "NotifierUseCase.followMove"
 
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	target.Address = address
+	moved := target.String()
+
+	err = n.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		subs, err := n.subStore.ListSubscriptionsByUserID(txCtx, userID)
 		if err != nil {
-			logger.L().Sugar().Errorf("%s: failed to process delivery %s: %v", op, delivery.ID, err)
+			return fmt.Errorf("%s: failed to list subscriptions of user %s: %w", op, userID, err)
+		}
+
+		for i := range subs {
+			sub := &subs[i]
+			if sub.Channel != channel {
+				continue
+			}
+			existing, err := n.subStore.GetSubscription(txCtx, sub.RepoID, userID, moved)
+			if err != nil {
+				return fmt.Errorf("%s: failed to check for existing subscription: %w", op, err)
+			}
+			if existing != nil {
+				if err := n.subStore.DeleteSubscriptionByID(txCtx, sub.ID); err != nil {
+					return fmt.Errorf("%s: failed to delete subscription %s: %w", op, sub.ID, err)
+				}
+				continue
+			}
+			sub.Channel = moved
+			sub.UpdatedAt = time.Now()
+			if err := n.subStore.UpdateSubscription(txCtx, sub); err != nil {
+				return fmt.Errorf("%s: failed to move subscription %s: %w", op, sub.ID, err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
+	return moved, nil
+}
 
-	logger.L().Sugar().Debugf("%s: finished notification cycle", op)
-	return nil
+// loadRepos fetches the repos of a batch of deliveries in one query, keyed by ID.
+func (n *notifierUseCase) loadRepos(ctx context.Context, deliveries []domain.Delivery) (map[uuid.UUID]*domain.Repo, error) {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, delivery := range deliveries {
+		if !seen[delivery.RepoID] {
+			seen[delivery.RepoID] = true
+			ids = append(ids, delivery.RepoID)
+		}
+	}
+
+	repos, err := n.repoStore.ListReposByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*domain.Repo, len(repos))
+	for i := range repos {
+		byID[repos[i].ID] = &repos[i]
+	}
+	return byID, nil
 }
@@ -6,16 +6,26 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mackb/releaseradar/internal/adapter/github"
 	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/adapter/queue"
 	"github.com/mackb/releaseradar/internal/domain"
-	"github.com/mackb/releaseradar/pkg/idempotency"
 	"github.com/mackb/releaseradar/pkg/logger"
 )
 
+// DefaultMaxNotFound is the number of consecutive 404 responses after which a repo is deactivated.
+const DefaultMaxNotFound = 5
+
+// reconcileWindow is how far back ReconcileDeliveries looks for releases nobody was notified about.
+const reconcileWindow = 7 * 24 * time.Hour
+
+// maxChecksumFileSize caps how much of a release's checksums file is downloaded.
+const maxChecksumFileSize = 1 << 20
+
 type pollerUseCase struct {
 	repoStore     persistence.RepoRepository
 	releaseStore  persistence.ReleaseRepository
@@ -24,9 +34,14 @@ type pollerUseCase struct {
 	deliveryStore persistence.DeliveryRepository // Добавлено
 	githubClient  github.Client
 	transactor    persistence.Transactor
+	publisher     queue.Publisher // Announces new deliveries to the notifier; may be nil
+	maxNotFound   int
 }
 
-func NewPollerUseCase(repoStore persistence.RepoRepository, releaseStore persistence.ReleaseRepository, subStore persistence.SubscriptionRepository, userStore persistence.UserRepository, deliveryStore persistence.DeliveryRepository, githubClient github.Client, transactor persistence.Transactor) PollerUseCase {
+func NewPollerUseCase(repoStore persistence.RepoRepository, releaseStore persistence.ReleaseRepository, subStore persistence.SubscriptionRepository, userStore persistence.UserRepository, deliveryStore persistence.DeliveryRepository, githubClient github.Client, transactor persistence.Transactor, publisher queue.Publisher, maxNotFound int) PollerUseCase {
+	if maxNotFound <= 0 {
+		maxNotFound = DefaultMaxNotFound
+	}
 	return &pollerUseCase{
 		repoStore:     repoStore,
 		releaseStore:  releaseStore,
@@ -35,6 +50,8 @@ func NewPollerUseCase(repoStore persistence.RepoRepository, releaseStore persist
 		deliveryStore: deliveryStore, // Добавлено
 		githubClient:  githubClient,
 		transactor:    transactor,
+		publisher:     publisher,
+		maxNotFound:   maxNotFound,
 	}
 }
 
@@ -42,55 +59,47 @@ func (p *pollerUseCase) PollReleases(ctx context.Context) error {
 	const op = "PollerUseCase.PollReleases"
 	logger.L().Sugar().Debugf("%s: starting release polling cycle", op)
 
-	// In a real application, this would iterate through all repos that need polling
-	// For a stub, we'll simulate polling for one existing repo (or create one)
-
-	// --- STUB: Fetch a dummy repo or create one if none exists ---
-	var repo *domain.Repo
-	repos, err := p.repoStore.ListRepos(ctx, uuid.Nil) // Assuming a method to list all repos or a specific user's repos
+	repos, err := p.repoStore.ListActiveRepos(ctx)
 	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
 		return fmt.Errorf("%s: failed to list repos: %w", op, err)
 	}
 
 	if len(repos) == 0 {
-		// Create a dummy user and repo if none exist
-		logger.L().Sugar().Warnf("%s: no repos found, creating a dummy user and repo for polling", op)
-		user := &domain.User{
-			ID:        uuid.New(),
-			Email:     "dummy@example.com",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		err = p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
-			if err := p.userStore.CreateUser(txCtx, user); err != nil { // Исправлено
-				return fmt.Errorf("%s: failed to create dummy user: %w", op, err)
-			}
-			dummyRepo := &domain.Repo{
-				ID:            uuid.New(),
-				UserID:        user.ID,
-				Owner:         "golang",
-				Name:          "go",
-				ETag:          "",
-				LastCheckedAt: time.Now().Add(-24 * time.Hour), // Set to past to trigger initial check
-				CreatedAt:     time.Now(),
-				UpdatedAt:     time.Now(),
-			}
-			if err := p.repoStore.CreateRepo(txCtx, dummyRepo); err != nil {
-				return fmt.Errorf("%s: failed to create dummy repo: %w", op, err)
-			}
-			repo = dummyRepo
-			return nil
-		})
-		if err != nil {
-			return fmt.Errorf("%s: failed to set up dummy repo for polling: %w", op, err)
+		logger.L().Sugar().Infof("%s: no active repos to poll", op)
+		return nil
+	}
+
+	for i := range repos {
+		if err := p.pollRepo(ctx, &repos[i]); err != nil {
+			logger.L().Sugar().Errorf("%s: failed to poll repo %s/%s (ID: %s): %v", op, repos[i].Owner, repos[i].Name, repos[i].ID, err)
 		}
-	} else {
-		repo = &repos[0] // Just pick the first one for the stub
 	}
-	// --- END STUB ---
 
+	logger.L().Sugar().Debugf("%s: finished release polling cycle", op)
+	return nil
+}
+
+func (p *pollerUseCase) pollRepo(ctx context.Context, repo *domain.Repo) error {
+	const op = "PollerUseCase.pollRepo"
 	logger.L().Sugar().Infof("%s: polling repo %s/%s (ID: %s)", op, repo.Owner, repo.Name, repo.ID)
 
+	if err := p.refreshRepo(ctx, repo); err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return p.recordRepoNotFound(ctx, repo)
+		}
+		return fmt.Errorf("%s: failed to refresh repo metadata: %w", op, err)
+	}
+
+	// Archived repos are read-only, so there is nothing new to pick up
+	if repo.Archived {
+		logger.L().Sugar().Debugf("%s: repo %s/%s is archived, skipping releases", op, repo.Owner, repo.Name)
+		repo.LastCheckedAt = time.Now()
+		if err := p.repoStore.UpdateRepo(ctx, repo); err != nil {
+			return fmt.Errorf("%s: failed to update repo %s: %w", op, repo.ID, err)
+		}
+		return nil
+	}
+
 	githubRelease, newETag, err := p.githubClient.GetLatestRelease(ctx, repo.Owner, repo.Name, repo.ETag)
 	if err != nil {
 		return fmt.Errorf("%s: failed to get latest release from GitHub for %s/%s: %w", op, repo.Owner, repo.Name, err)
@@ -99,43 +108,32 @@ func (p *pollerUseCase) PollReleases(ctx context.Context) error {
 	if githubRelease == nil {
 		logger.L().Sugar().Infof("%s: no new release or content not modified for %s/%s", op, repo.Owner, repo.Name)
 	} else {
-		// Calculate hash of release content (e.g., body + tag + title + url)
-		hash := sha256.New()
-		hash.Write([]byte(githubRelease.Body + githubRelease.Tag + githubRelease.Title + githubRelease.URL))
-		releaseHash := hex.EncodeToString(hash.Sum(nil))
-
 		existingRelease, err := p.releaseStore.GetReleaseByRepoIDAndTag(ctx, repo.ID, githubRelease.Tag)
 		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
 			return fmt.Errorf("%s: failed to check for existing release: %w", op, err)
 		}
 
-		// Only create a new release if it's genuinely new or content has changed
-		if existingRelease == nil || existingRelease.Hash != releaseHash {
-			newRelease := &domain.Release{
-				ID:          uuid.New(),
-				RepoID:      repo.ID,
-				Tag:         githubRelease.Tag,
-				Title:       githubRelease.Title,
-				URL:         githubRelease.URL,
-				PublishedAt: githubRelease.PublishedAt,
-				Hash:        releaseHash,
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
+		if existingRelease == nil {
+			// The new ETag is stored with the release, so a crash cannot skip past it
+			if err := p.createRelease(ctx, repo, githubRelease, newETag); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
 			}
-			if err := p.releaseStore.CreateRelease(ctx, newRelease); err != nil {
-				return fmt.Errorf("%s: failed to create new release: %w", op, err)
+		} else {
+			if err := p.updateRelease(ctx, repo, existingRelease, githubRelease); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
 			}
-			logger.L().Sugar().Infof("%s: new release %s for %s/%s", op, newRelease.Tag, repo.Owner, repo.Name)
-
-			// Enqueue deliveries for this new release
-			if err := p.EnqueueDeliveries(ctx, newRelease); err != nil {
-				logger.L().Sugar().Errorf("%s: failed to enqueue deliveries for release %s: %v", op, newRelease.ID, err)
+			// Keep download counts of the latest release current
+			if err := p.syncAssets(ctx, existingRelease, githubRelease); err != nil {
+				logger.L().Sugar().Errorf("%s: failed to sync assets for release %s: %v", op, existingRelease.ID, err)
 			}
-		} else {
-			logger.L().Sugar().Infof("%s: release %s for %s/%s already exists with same content", op, githubRelease.Tag, repo.Owner, repo.Name)
 		}
 	}
 
+	// Edits and retractions can happen to any release, not just the latest one
+	if err := p.syncReleaseHistory(ctx, repo); err != nil {
+		logger.L().Sugar().Errorf("%s: failed to sync release history for %s/%s: %v", op, repo.Owner, repo.Name, err)
+	}
+
 	// Update repo ETag and LastCheckedAt
 	if newETag != "" && newETag != repo.ETag {
 		repo.ETag = newETag
@@ -145,48 +143,581 @@ func (p *pollerUseCase) PollReleases(ctx context.Context) error {
 		return fmt.Errorf("%s: failed to update repo %s ETag/LastCheckedAt: %w", op, repo.ID, err)
 	}
 
-	logger.L().Sugar().Debugf("%s: finished release polling cycle", op)
+	return nil
+}
+
+// refreshRepo syncs the repo's identity and state with GitHub. Renamed or transferred repos
+// are looked up by their numeric ID, so owner and name follow the repo to its new location.
+func (p *pollerUseCase) refreshRepo(ctx context.Context, repo *domain.Repo) error {
+	const op = "PollerUseCase.refreshRepo"
+
+	var current *github.Repository
+	var err error
+	if repo.GithubID != 0 {
+		current, err = p.githubClient.GetRepositoryByID(ctx, repo.GithubID)
+	} else {
+		current, err = p.githubClient.GetRepository(ctx, repo.Owner, repo.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.Owner != repo.Owner || current.Name != repo.Name {
+		logger.L().Sugar().Infof("%s: repo %s/%s moved to %s/%s", op, repo.Owner, repo.Name, current.Owner, current.Name)
+		repo.Owner = current.Owner
+		repo.Name = current.Name
+		// The cached ETags belong to the old URL
+		repo.ETag = ""
+		repo.ReleasesETag = ""
+	}
+	if current.Archived != repo.Archived {
+		logger.L().Sugar().Infof("%s: repo %s/%s archived: %t", op, repo.Owner, repo.Name, current.Archived)
+	}
+	repo.GithubID = current.ID
+	repo.Archived = current.Archived
+	repo.NotFoundCount = 0
+	repo.UpdatedAt = time.Now()
+	return nil
+}
+
+// recordRepoNotFound counts a 404 for the repo and deactivates it once the limit is reached,
+// notifying its subscribers once.
+func (p *pollerUseCase) recordRepoNotFound(ctx context.Context, repo *domain.Repo) error {
+	const op = "PollerUseCase.recordRepoNotFound"
+
+	repo.NotFoundCount++
+	repo.LastCheckedAt = time.Now()
+	repo.UpdatedAt = time.Now()
+	deactivate := repo.NotFoundCount >= p.maxNotFound
+	if deactivate {
+		now := time.Now()
+		repo.DeactivatedAt = &now
+	}
+
+	if !deactivate {
+		if err := p.repoStore.UpdateRepo(ctx, repo); err != nil {
+			return fmt.Errorf("%s: failed to update repo %s: %w", op, repo.ID, err)
+		}
+		logger.L().Sugar().Warnf("%s: repo %s/%s not found on GitHub (%d/%d)", op, repo.Owner, repo.Name, repo.NotFoundCount, p.maxNotFound)
+		return nil
+	}
+
+	logger.L().Sugar().Warnf("%s: repo %s/%s not found %d times in a row, deactivating", op, repo.Owner, repo.Name, repo.NotFoundCount)
+	var enqueued []uuid.UUID
+	err := p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if err := p.repoStore.UpdateRepo(txCtx, repo); err != nil {
+			return fmt.Errorf("%s: failed to update repo %s: %w", op, repo.ID, err)
+		}
+		var err error
+		if enqueued, err = p.enqueueDeliveries(txCtx, repo.ID, nil, domain.DeliveryKindRepoDeactivated, nil, nil); err != nil {
+			return fmt.Errorf("%s: failed to enqueue deactivation notices for repo %s: %w", op, repo.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.publish(ctx, enqueued)
+	return nil
+}
+
+// createRelease stores a release seen upstream for the first time with its assets, enqueues
+// deliveries for it and advances the repo's ETag, all in one transaction: either subscribers
+// are notified, or the release is picked up again on the next poll.
+func (p *pollerUseCase) createRelease(ctx context.Context, repo *domain.Repo, githubRelease *github.Release, newETag string) error {
+	const op = "PollerUseCase.createRelease"
+
+	newRelease := &domain.Release{
+		ID:          uuid.New(),
+		RepoID:      repo.ID,
+		Tag:         githubRelease.Tag,
+		Title:       githubRelease.Title,
+		URL:         githubRelease.URL,
+		Body:        githubRelease.Body,
+		Prerelease:  githubRelease.Prerelease,
+		Author:      githubRelease.Author,
+		PublishedAt: githubRelease.PublishedAt,
+		Hash:        releaseHash(githubRelease),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	// Checksums are downloaded before the transaction, so it does not wait on GitHub
+	assets := p.resolveAssets(ctx, newRelease, githubRelease, nil)
+
+	var enqueued []uuid.UUID
+	err := p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if err := p.releaseStore.CreateRelease(txCtx, newRelease); err != nil {
+			return fmt.Errorf("%s: failed to create new release: %w", op, err)
+		}
+		if err := p.releaseStore.CreateReleaseRevision(txCtx, newRevision(newRelease, "")); err != nil {
+			return fmt.Errorf("%s: failed to record initial revision: %w", op, err)
+		}
+		if err := p.releaseStore.ReplaceReleaseAssets(txCtx, newRelease.ID, assets); err != nil {
+			return fmt.Errorf("%s: failed to store assets: %w", op, err)
+		}
+		var err error
+		if enqueued, err = p.enqueueDeliveries(txCtx, newRelease.RepoID, newRelease, domain.DeliveryKindRelease, nil, nil); err != nil {
+			return fmt.Errorf("%s: failed to enqueue deliveries: %w", op, err)
+		}
+
+		if newETag != "" {
+			repo.ETag = newETag
+		}
+		repo.LastCheckedAt = time.Now()
+		if err := p.repoStore.UpdateRepo(txCtx, repo); err != nil {
+			return fmt.Errorf("%s: failed to update repo %s ETag: %w", op, repo.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.L().Sugar().Infof("%s: new release %s for %s/%s", op, newRelease.Tag, repo.Owner, repo.Name)
+	p.publish(ctx, enqueued)
+	return nil
+}
+
+// updateRelease brings a stored release in line with its upstream counterpart. A content change
+// is recorded as a new revision and announced to subscribers who opted in to updates.
+func (p *pollerUseCase) updateRelease(ctx context.Context, repo *domain.Repo, release *domain.Release, githubRelease *github.Release) error {
+	const op = "PollerUseCase.updateRelease"
+
+	hash := releaseHash(githubRelease)
+	restored := release.DeletedAt != nil
+	if release.Prerelease != githubRelease.Prerelease || release.Author != githubRelease.Author {
+		// Promoting a prerelease to a full release does not change its content hash, and
+		// releases stored before authors were tracked pick theirs up here
+		release.Prerelease = githubRelease.Prerelease
+		release.Author = githubRelease.Author
+		release.UpdatedAt = time.Now()
+		if err := p.releaseStore.UpdateRelease(ctx, release); err != nil {
+			return fmt.Errorf("%s: failed to update metadata of release %s: %w", op, release.ID, err)
+		}
+	}
+
+	if release.Hash == hash && !restored {
+		logger.L().Sugar().Debugf("%s: release %s for %s/%s already exists with same content", op, release.Tag, repo.Owner, repo.Name)
+		return nil
+	}
+
+	if restored {
+		logger.L().Sugar().Infof("%s: release %s for %s/%s reappeared upstream", op, release.Tag, repo.Owner, repo.Name)
+		release.DeletedAt = nil
+	}
+
+	if release.Hash == hash {
+		release.UpdatedAt = time.Now()
+		if err := p.releaseStore.UpdateRelease(ctx, release); err != nil {
+			return fmt.Errorf("%s: failed to restore release %s: %w", op, release.ID, err)
+		}
+		return nil
+	}
+
+	summary := summarizeReleaseChange(release, githubRelease)
+	release.Title = githubRelease.Title
+	release.URL = githubRelease.URL
+	release.Body = githubRelease.Body
+	release.Hash = hash
+	release.UpdatedAt = time.Now()
+	revision := newRevision(release, summary)
+
+	var enqueued []uuid.UUID
+	err := p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if err := p.releaseStore.UpdateRelease(txCtx, release); err != nil {
+			return fmt.Errorf("%s: failed to update release %s: %w", op, release.ID, err)
+		}
+		if err := p.releaseStore.CreateReleaseRevision(txCtx, revision); err != nil {
+			return fmt.Errorf("%s: failed to record revision for release %s: %w", op, release.ID, err)
+		}
+		wantsUpdates := func(sub domain.Subscription) bool { return sub.NotifyOnUpdate }
+		var err error
+		if enqueued, err = p.enqueueDeliveries(txCtx, release.RepoID, release, domain.DeliveryKindReleaseUpdated, &revision.ID, wantsUpdates); err != nil {
+			return fmt.Errorf("%s: failed to enqueue update deliveries for release %s: %w", op, release.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.L().Sugar().Infof("%s: release %s for %s/%s was edited: %s", op, release.Tag, repo.Owner, repo.Name, summary)
+	p.publish(ctx, enqueued)
+	return nil
+}
+
+// syncAssets stores the release's asset list.
+func (p *pollerUseCase) syncAssets(ctx context.Context, release *domain.Release, githubRelease *github.Release) error {
+	const op = "PollerUseCase.syncAssets"
+
+	stored, err := p.releaseStore.ListReleaseAssets(ctx, release.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to list stored assets: %w", op, err)
+	}
+
+	assets := p.resolveAssets(ctx, release, githubRelease, stored)
+	if err := p.releaseStore.ReplaceReleaseAssets(ctx, release.ID, assets); err != nil {
+		return fmt.Errorf("%s: failed to store assets: %w", op, err)
+	}
+	return nil
+}
+
+// resolveAssets builds the release's asset list from upstream, keeping what is known about
+// stored assets. Digests come from a checksums file attached to the release, which is only
+// downloaded when an asset is new, changed in size, or still has no digest, e.g. because an
+// earlier download of the checksums file failed.
+func (p *pollerUseCase) resolveAssets(ctx context.Context, release *domain.Release, githubRelease *github.Release, stored []domain.ReleaseAsset) []domain.ReleaseAsset {
+	const op = "PollerUseCase.resolveAssets"
+
+	storedByName := make(map[string]domain.ReleaseAsset, len(stored))
+	for _, asset := range stored {
+		storedByName[asset.Name] = asset
+	}
+
+	assets := make([]domain.ReleaseAsset, 0, len(githubRelease.Assets))
+	checksumURL := ""
+	needDigests := false
+	for _, a := range githubRelease.Assets {
+		if github.IsChecksumFile(a.Name) {
+			checksumURL = a.DownloadURL
+		}
+
+		asset := domain.ReleaseAsset{
+			ID:            uuid.New(),
+			ReleaseID:     release.ID,
+			Name:          a.Name,
+			Size:          a.Size,
+			ContentType:   a.ContentType,
+			DownloadURL:   a.DownloadURL,
+			DownloadCount: a.DownloadCount,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if prev, ok := storedByName[a.Name]; ok && prev.Size == a.Size {
+			asset.ID = prev.ID
+			asset.SHA256 = prev.SHA256
+			asset.CreatedAt = prev.CreatedAt
+		} else {
+			needDigests = true
+		}
+		// The checksums file does not list itself
+		if asset.SHA256 == "" && !github.IsChecksumFile(a.Name) {
+			needDigests = true
+		}
+		assets = append(assets, asset)
+	}
+
+	if checksumURL != "" && needDigests {
+		content, err := p.githubClient.DownloadAsset(ctx, checksumURL, maxChecksumFileSize)
+		if err != nil {
+			logger.L().Sugar().Warnf("%s: failed to download checksums for release %s, storing assets without digests: %v", op, release.ID, err)
+		} else {
+			digests := github.ParseChecksums(content)
+			for i := range assets {
+				if digest, ok := digests[assets[i].Name]; ok {
+					assets[i].SHA256 = digest
+				}
+			}
+		}
+	}
+	return assets
+}
+
+// markReleaseDeleted flags a release that is no longer published upstream, e.g. retracted or yanked.
+func (p *pollerUseCase) markReleaseDeleted(ctx context.Context, repo *domain.Repo, release *domain.Release) error {
+	const op = "PollerUseCase.markReleaseDeleted"
+
+	now := time.Now()
+	release.DeletedAt = &now
+	release.UpdatedAt = now
+	var enqueued []uuid.UUID
+	err := p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if err := p.releaseStore.UpdateRelease(txCtx, release); err != nil {
+			return fmt.Errorf("%s: failed to mark release %s deleted: %w", op, release.ID, err)
+		}
+		wantsRetractions := func(sub domain.Subscription) bool { return sub.NotifyOnDelete }
+		var err error
+		if enqueued, err = p.enqueueDeliveries(txCtx, release.RepoID, release, domain.DeliveryKindReleaseDeleted, nil, wantsRetractions); err != nil {
+			return fmt.Errorf("%s: failed to enqueue retraction deliveries for release %s: %w", op, release.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.L().Sugar().Infof("%s: release %s for %s/%s disappeared upstream", op, release.Tag, repo.Owner, repo.Name)
+	p.publish(ctx, enqueued)
+	return nil
+}
+
+// syncReleaseHistory compares stored releases against the upstream release list to pick up
+// edits of older releases and releases that were removed. The list is requested with its
+// ETag, so an unchanged list costs a 304 and no further work.
+func (p *pollerUseCase) syncReleaseHistory(ctx context.Context, repo *domain.Repo) error {
+	const op = "PollerUseCase.syncReleaseHistory"
+
+	upstream, newETag, err := p.githubClient.ListReleases(ctx, repo.Owner, repo.Name, repo.ReleasesETag)
+	if err != nil {
+		return fmt.Errorf("%s: failed to list releases from GitHub: %w", op, err)
+	}
+	if upstream == nil {
+		logger.L().Sugar().Debugf("%s: release list of %s/%s not modified", op, repo.Owner, repo.Name)
+		return nil
+	}
+
+	stored, err := p.releaseStore.ListReleasesByRepoID(ctx, repo.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to list stored releases: %w", op, err)
+	}
+
+	upstreamByTag := make(map[string]*github.Release, len(upstream))
+	for i := range upstream {
+		upstreamByTag[upstream[i].Tag] = &upstream[i]
+	}
+
+	// A full page means older releases were cut off, so only releases published
+	// since the oldest listed one can be judged missing.
+	var horizon time.Time
+	if len(upstream) >= github.ReleasesPageSize {
+		horizon = upstream[0].PublishedAt
+		for _, rel := range upstream {
+			if rel.PublishedAt.Before(horizon) {
+				horizon = rel.PublishedAt
+			}
+		}
+	}
+
+	for i := range stored {
+		release := &stored[i]
+		if githubRelease, ok := upstreamByTag[release.Tag]; ok {
+			if err := p.updateRelease(ctx, repo, release, githubRelease); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+		if release.DeletedAt == nil && !release.PublishedAt.Before(horizon) {
+			if err := p.markReleaseDeleted(ctx, repo, release); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	// Only remembered once the list was fully applied, so a failure is retried on the next poll
+	repo.ReleasesETag = newETag
+	return nil
+}
+
+// ReconcileDeliveries backfills the deliveries of recent releases that have subscribers but no
+// deliveries, e.g. releases stored before delivery enqueueing was transactional. Only those
+// subscribed and not muted when the release was created are notified, so releases published
+// during a mute are not sent once it ends.
+func (p *pollerUseCase) ReconcileDeliveries(ctx context.Context) error {
+	const op = "PollerUseCase.ReconcileDeliveries"
+	logger.L().Sugar().Debugf("%s: starting delivery reconciliation", op)
+
+	releases, err := p.releaseStore.ListReleasesWithoutDeliveries(ctx, time.Now().Add(-reconcileWindow))
+	if err != nil {
+		return fmt.Errorf("%s: failed to list releases without deliveries: %w", op, err)
+	}
+
+	for i := range releases {
+		release := &releases[i]
+		// Only those who would have been notified when the release was created: already
+		// subscribed, and not muted at the time
+		wouldHaveBeenNotified := func(sub domain.Subscription) bool {
+			return !sub.CreatedAt.After(release.CreatedAt) && (sub.MutedUntil == nil || !release.CreatedAt.Before(*sub.MutedUntil))
+		}
+		var enqueued []uuid.UUID
+		err := p.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+			var err error
+			enqueued, err = p.enqueueDeliveries(txCtx, release.RepoID, release, domain.DeliveryKindRelease, nil, wouldHaveBeenNotified)
+			return err
+		})
+		if err != nil {
+			logger.L().Sugar().Errorf("%s: failed to backfill deliveries for release %s: %v", op, release.ID, err)
+			continue
+		}
+		if len(enqueued) == 0 {
+			logger.L().Sugar().Debugf("%s: nothing to send for release %s (%s) of repo %s", op, release.ID, release.Tag, release.RepoID)
+			continue
+		}
+		p.publish(ctx, enqueued)
+		logger.L().Sugar().Infof("%s: backfilled %d deliveries for release %s (%s) of repo %s", op, len(enqueued), release.ID, release.Tag, release.RepoID)
+	}
+
+	logger.L().Sugar().Debugf("%s: finished delivery reconciliation", op)
 	return nil
 }
 
 func (p *pollerUseCase) EnqueueDeliveries(ctx context.Context, release *domain.Release) error {
+	enqueued, err := p.enqueueDeliveries(ctx, release.RepoID, release, domain.DeliveryKindRelease, nil, nil)
+	if err != nil {
+		return err
+	}
+	p.publish(ctx, enqueued)
+	return nil
+}
+
+// publish announces deliveries to the notifier once they are committed. Postgres stays the
+// source of truth: should publishing fail, the notifier's periodic sweep picks them up.
+func (p *pollerUseCase) publish(ctx context.Context, ids []uuid.UUID) {
+	const op = "PollerUseCase.publish"
+
+	if p.publisher == nil || len(ids) == 0 {
+		return
+	}
+	if err := p.publisher.PublishDeliveries(ctx, ids); err != nil {
+		logger.L().Sugar().Warnf("%s: failed to announce %d deliveries, leaving them to the notifier sweep: %v", op, len(ids), err)
+	}
+}
+
+// enqueueDeliveries creates a delivery of the given kind for every subscriber of the repo.
+// release is nil for repo-level notices. If want is non-nil, only subscriptions it accepts
+// receive a delivery. It stops at the first delivery that cannot be created, so that callers
+// running it in a transaction roll back rather than lose deliveries. It returns the IDs of the
+// deliveries that are ready to send, for callers to publish once they are committed.
+func (p *pollerUseCase) enqueueDeliveries(ctx context.Context, repoID uuid.UUID, release *domain.Release, kind string, revisionID *uuid.UUID, want func(domain.Subscription) bool) ([]uuid.UUID, error) {
 	const op = "PollerUseCase.EnqueueDeliveries"
-	logger.L().Sugar().Debugf("%s: enqueuing deliveries for release %s", op, release.ID)
+	logger.L().Sugar().Debugf("%s: enqueuing %s deliveries for repo %s", op, kind, repoID)
+
+	var releaseID *uuid.UUID
+	if release != nil {
+		releaseID = &release.ID
+	}
 
-	subscriptions, err := p.subStore.ListSubscriptionsByRepoID(ctx, release.RepoID)
+	subscriptions, err := p.subStore.ListSubscriptionsByRepoID(ctx, repoID)
 	if err != nil {
-		return fmt.Errorf("%s: failed to get subscriptions for repo %s: %w", op, release.RepoID, err)
+		return nil, fmt.Errorf("%s: failed to get subscriptions for repo %s: %w", op, repoID, err)
 	}
 
+	var ready []uuid.UUID
+	// Timezones of the users with digest subscriptions, loaded once per user
+	locations := make(map[uuid.UUID]*time.Location)
+
 	for _, sub := range subscriptions {
-		// Check for idempotency for this specific delivery
-		deliveryKey := fmt.Sprintf("delivery:%s:%s:%s", release.ID, sub.UserID, sub.Channel)
-		idempotencyManager := idempotency.NewManager(nil) // Needs a Redis-backed storage
-
-		// --- STUB: Replace with actual RedisIdempotencyStorage when available ---
-		// For now, simulate idempotency check by always returning true for simplicity
-		// and directly creating the delivery.
-		// In a real scenario, this would interact with Redis.
-		_ = deliveryKey        // Avoid unused variable warning
-		_ = idempotencyManager // Avoid unused variable warning
-		// --- END STUB ---
+		if sub.DisabledAt != nil || (sub.MutedUntil != nil && time.Now().Before(*sub.MutedUntil)) {
+			continue
+		}
+		if sub.MajorOnly && release != nil && release.Type() != domain.ReleaseTypeMajor {
+			continue
+		}
+		if want != nil && !want(sub) {
+			continue
+		}
 
 		delivery := &domain.Delivery{
-			ID:        uuid.New(),
-			ReleaseID: release.ID,
-			UserID:    sub.UserID,
-			Channel:   sub.Channel,
-			Status:    "pending",
-			Attempt:   0,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			ID:         uuid.New(),
+			RepoID:     repoID,
+			ReleaseID:  releaseID,
+			RevisionID: revisionID,
+			UserID:     sub.UserID,
+			Channel:    sub.Channel,
+			Kind:       kind,
+			Status:     "pending",
+			Attempt:    0,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		// A release can be restored and retracted again; each retraction is an event of its own
+		if kind == domain.DeliveryKindReleaseDeleted {
+			delivery.RetractedAt = release.DeletedAt
+		}
+		// Digest subscriptions hold the delivery until their next digest is due
+		if sub.IsDigest() {
+			next := sub.NextDigest(time.Now(), p.userLocation(ctx, sub.UserID, locations))
+			delivery.Status = "digest"
+			delivery.ScheduledAt = &next
 		}
-		if err := p.deliveryStore.CreateDelivery(ctx, delivery); err != nil { // Исправлено
-			logger.L().Sugar().Errorf("%s: failed to create delivery for release %s, user %s, channel %s: %v", op, release.ID, sub.UserID, sub.Channel, err)
+		if err := p.deliveryStore.CreateDelivery(ctx, delivery); err != nil {
+			if errors.Is(err, persistence.ErrDuplicateDelivery) {
+				logger.L().Sugar().Infof("%s: %s delivery for repo %s, user %s, channel %s already exists, skipping", op, kind, repoID, sub.UserID, sub.Channel)
+				continue
+			}
+			return nil, fmt.Errorf("%s: failed to create %s delivery for repo %s, user %s, channel %s: %w", op, kind, repoID, sub.UserID, sub.Channel, err)
+		}
+		if delivery.Status == "pending" {
+			ready = append(ready, delivery.ID)
 		}
-		logger.L().Sugar().Debugf("%s: enqueued delivery %s for release %s, user %s, channel %s", op, delivery.ID, release.ID, sub.UserID, sub.Channel)
+		logger.L().Sugar().Debugf("%s: enqueued %s delivery %s for repo %s, user %s, channel %s", op, kind, delivery.ID, repoID, sub.UserID, sub.Channel)
 	}
 
-	logger.L().Sugar().Debugf("%s: finished enqueuing deliveries for release %s", op, release.ID)
-	return nil
+	logger.L().Sugar().Debugf("%s: finished enqueuing %s deliveries for repo %s", op, kind, repoID)
+	return ready, nil
+}
+
+// userLocation returns the timezone of a user's digest schedules, caching it in locations.
+// Should the user fail to load, digests are scheduled in UTC.
+func (p *pollerUseCase) userLocation(ctx context.Context, userID uuid.UUID, locations map[uuid.UUID]*time.Location) *time.Location {
+	const op = "PollerUseCase.userLocation"
+
+	if loc, ok := locations[userID]; ok {
+		return loc
+	}
+
+	loc := time.UTC
+	user, err := p.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.L().Sugar().Warnf("%s: failed to get user %s, scheduling digests in UTC: %v", op, userID, err)
+	} else if user != nil {
+		loc = user.Location()
+	}
+	locations[userID] = loc
+	return loc
+}
+
+// releaseHash calculates a hash of release content (body + tag + title + url).
+func releaseHash(release *github.Release) string {
+	hash := sha256.New()
+	hash.Write([]byte(release.Body + release.Tag + release.Title + release.URL))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func newRevision(release *domain.Release, summary string) *domain.ReleaseRevision {
+	return &domain.ReleaseRevision{
+		ID:        uuid.New(),
+		ReleaseID: release.ID,
+		Tag:       release.Tag,
+		Title:     release.Title,
+		URL:       release.URL,
+		Body:      release.Body,
+		Hash:      release.Hash,
+		Summary:   summary,
+		CreatedAt: time.Now(),
+	}
+}
+
+// summarizeReleaseChange describes how an upstream release differs from the stored one.
+func summarizeReleaseChange(old *domain.Release, updated *github.Release) string {
+	var changes []string
+	if old.Title != updated.Title {
+		changes = append(changes, fmt.Sprintf("title changed from %q to %q", old.Title, updated.Title))
+	}
+	if old.URL != updated.URL {
+		changes = append(changes, "URL changed")
+	}
+	if old.Body != updated.Body {
+		added, removed := diffLines(old.Body, updated.Body)
+		changes = append(changes, fmt.Sprintf("release notes changed (+%d/-%d lines)", added, removed))
+	}
+	if len(changes) == 0 {
+		return "content changed"
+	}
+	return strings.Join(changes, "; ")
+}
+
+// diffLines counts lines added to and removed from old to produce updated, ignoring order.
+func diffLines(old, updated string) (added, removed int) {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(old, "\n") {
+		counts[strings.TrimSpace(line)]++
+	}
+	for _, line := range strings.Split(updated, "\n") {
+		counts[strings.TrimSpace(line)]--
+	}
+	for _, c := range counts {
+		if c > 0 {
+			removed += c
+		} else {
+			added -= c
+		}
+	}
+	return added, removed
 }
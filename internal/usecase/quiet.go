@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+// ReleaseDeferred releases the deliveries held by quiet hours that have ended. They go back to
+// the notifier one by one, or, if the quiet hours bundle them, as one digest per user and channel.
+func (n *notifierUseCase) ReleaseDeferred(ctx context.Context) error {
+	const op = "NotifierUseCase.ReleaseDeferred"
+	logger.L().Sugar().Debugf("%s: starting release of deferred deliveries", op)
+
+	deliveries, err := n.deliveryStore.ListDueDeliveries(ctx, "deferred", time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: failed to list due deferred deliveries: %w", op, err)
+	}
+
+	if len(deliveries) == 0 {
+		logger.L().Sugar().Debugf("%s: no deferred deliveries due", op)
+		return nil
+	}
+
+	logger.L().Sugar().Infof("%s: found %d deferred deliveries to release", op, len(deliveries))
+
+	repos, err := n.loadRepos(ctx, deliveries)
+	if err != nil {
+		return fmt.Errorf("%s: failed to load repos: %w", op, err)
+	}
+
+	groups := make(map[digestKey][]domain.Delivery)
+	var keys []digestKey
+	for _, delivery := range deliveries {
+		key := digestKey{userID: delivery.UserID, channel: delivery.Channel}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], delivery)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+
+		quiet, err := effectiveQuietHours(ctx, n.quietStore, key.userID, key.channel)
+		if err != nil {
+			logger.L().Sugar().Errorf("%s: failed to get quiet hours of user %s on channel %s: %v", op, key.userID, key.channel, err)
+			continue
+		}
+
+		if quiet != nil && quiet.Bundle && len(group) > 1 {
+			idempotencyKey := fmt.Sprintf("digest:%s:%d", group[0].ID, group[0].Attempt)
+			err = n.idempotencyManager.Do(ctx, idempotencyKey, 10*time.Minute, func() error {
+				return n.sendDigest(ctx, key.channel, group, repos)
+			})
+		} else {
+			ids := make([]uuid.UUID, len(group))
+			for i := range group {
+				ids[i] = group[i].ID
+			}
+			err = n.deliveryStore.ScheduleDeliveries(ctx, ids, "pending", nil)
+		}
+		if err != nil {
+			logger.L().Sugar().Errorf("%s: failed to release deferred deliveries of user %s on channel %s: %v", op, key.userID, key.channel, err)
+		}
+	}
+
+	logger.L().Sugar().Debugf("%s: finished release of deferred deliveries", op)
+	return nil
+}
+
+// quietUntil returns when the quiet hours the user is in on channel end, or the zero time if
+// the message may be sent now. Security releases pass when the quiet hours allow them.
+func (n *notifierUseCase) quietUntil(ctx context.Context, user *domain.User, channel string, release *domain.Release) (time.Time, error) {
+	quiet, err := effectiveQuietHours(ctx, n.quietStore, user.ID, channel)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if quiet == nil || (quiet.AllowSecurity && release != nil && release.IsSecurity()) {
+		return time.Time{}, nil
+	}
+
+	until, ok := quiet.Until(time.Now(), user.Location())
+	if !ok {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// effectiveQuietHours returns the quiet hours that apply to a user's channel: the channel's
+// own, else the user's default. It returns nil if there are none.
+func effectiveQuietHours(ctx context.Context, store persistence.QuietHoursRepository, userID uuid.UUID, channel string) (*domain.QuietHours, error) {
+	quiet, err := store.GetQuietHours(ctx, userID, channel)
+	if err != nil || quiet != nil {
+		return quiet, err
+	}
+	return store.GetQuietHours(ctx, userID, "")
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/domain"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+type releaseUseCase struct {
+	releaseStore persistence.ReleaseRepository
+}
+
+func NewReleaseUseCase(releaseStore persistence.ReleaseRepository) ReleaseUseCase {
+	return &releaseUseCase{releaseStore: releaseStore}
+}
+
+func (r *releaseUseCase) ListReleaseAssets(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseAsset, error) {
+	const op = "ReleaseUseCase.ListReleaseAssets"
+	logger.L().Sugar().Debugf("%s: attempting to list assets for release %s", op, releaseID)
+
+	release, err := r.releaseStore.GetReleaseByID(ctx, releaseID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get release %s: %w", op, releaseID, err)
+	}
+	if release == nil {
+		return nil, fmt.Errorf("%s: release %s: %w", op, releaseID, persistence.ErrNotFound)
+	}
+
+	assets, err := r.releaseStore.ListReleaseAssets(ctx, releaseID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list assets for release %s: %w", op, releaseID, err)
+	}
+
+	return assets, nil
+}
+
+// GetRelease returns a release by ID, or persistence.ErrNotFound.
+func (r *releaseUseCase) GetRelease(ctx context.Context, releaseID uuid.UUID) (*domain.Release, error) {
+	const op = "ReleaseUseCase.GetRelease"
+	logger.L().Sugar().Debugf("%s: attempting to get release %s", op, releaseID)
+
+	release, err := r.releaseStore.GetReleaseByID(ctx, releaseID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get release %s: %w", op, releaseID, err)
+	}
+	if release == nil {
+		return nil, fmt.Errorf("%s: release %s: %w", op, releaseID, persistence.ErrNotFound)
+	}
+	return release, nil
+}
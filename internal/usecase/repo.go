@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -44,7 +45,7 @@ func (r *repoUseCase) AddRepo(ctx context.Context, userID uuid.UUID, owner, name
 			return fmt.Errorf("%s: failed to check for existing repo: %w", op, err)
 		}
 		if existingRepo != nil {
-			return fmt.Errorf("%s: repo %s/%s already exists", op, owner, name)
+			return fmt.Errorf("%s: repo %s/%s: %w", op, owner, name, ErrAlreadyExists)
 		}
 
 		newRepo := &domain.Repo{
@@ -98,3 +99,55 @@ func (r *repoUseCase) GetRepoByID(ctx context.Context, repoID uuid.UUID) (*domai
 
 	return repo, nil
 }
+
+// GetRepoByName returns the tracked repo owner/name, or nil if it is not tracked.
+func (r *repoUseCase) GetRepoByName(ctx context.Context, owner, name string) (*domain.Repo, error) {
+	const op = "RepoUseCase.GetRepoByName"
+	logger.L().Sugar().Debugf("%s: attempting to get repo %s/%s", op, owner, name)
+
+	repo, err := r.repoStore.GetRepoByOwnerAndName(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get repo %s/%s: %w", op, owner, name, err)
+	}
+
+	return repo, nil
+}
+
+// FindOrAddRepo returns the tracked repo owner/name, adding it for userID if nobody tracks it
+// yet. Repos are checked on GitHub before they are added, and stored under their canonical
+// name; persistence.ErrNotFound is returned for repos GitHub does not know.
+func (r *repoUseCase) FindOrAddRepo(ctx context.Context, userID uuid.UUID, owner, name string) (*domain.Repo, error) {
+	const op = "RepoUseCase.FindOrAddRepo"
+	logger.L().Sugar().Debugf("%s: looking up repo %s/%s for user %s", op, owner, name, userID)
+
+	repo, err := r.repoStore.GetRepoByOwnerAndName(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get repo %s/%s: %w", op, owner, name, err)
+	}
+	if repo != nil {
+		return repo, nil
+	}
+
+	githubRepo, err := r.githubClient.GetRepository(ctx, owner, name)
+	if errors.Is(err, github.ErrNotFound) {
+		return nil, fmt.Errorf("%s: repo %s/%s: %w", op, owner, name, persistence.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get repo %s/%s from github: %w", op, owner, name, err)
+	}
+
+	// The input may differ from GitHub's spelling, or point at a renamed repo we already track
+	repo, err = r.repoStore.GetRepoByOwnerAndName(ctx, githubRepo.Owner, githubRepo.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get repo %s/%s: %w", op, githubRepo.Owner, githubRepo.Name, err)
+	}
+	if repo != nil {
+		return repo, nil
+	}
+
+	repo, err = r.AddRepo(ctx, userID, githubRepo.Owner, githubRepo.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return repo, nil
+}
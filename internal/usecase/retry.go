@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/mackb/releaseradar/pkg/retry"
+)
+
+// RetryPolicy says how often and how fast failed deliveries are retried before they are
+// dead-lettered. Zero fields take the value of DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts, including the first, after which a delivery is "dead"
+	BaseDelay   time.Duration // Delay after the first failure, doubled after each further one
+	MaxDelay    time.Duration // Cap on the delay between attempts
+}
+
+// DefaultRetryPolicy retries for about an hour: after 30s, 1m, 2m, ... up to 8 attempts.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 8, BaseDelay: 30 * time.Second, MaxDelay: time.Hour}
+
+// withDefaults fills in the zero fields of p from DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// next returns the status of a delivery whose attempt failed, "failed" or "dead", and for
+// "failed" when it is retried.
+func (p RetryPolicy) next(attempt int, now time.Time) (string, *time.Time) {
+	if attempt >= p.MaxAttempts {
+		return "dead", nil
+	}
+	at := now.Add(retry.Backoff(attempt, p.BaseDelay, p.MaxDelay))
+	return "failed", &at
+}
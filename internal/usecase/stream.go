@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/queue"
+	"github.com/mackb/releaseradar/pkg/logger"
+)
+
+const (
+	streamReadCount  = 100              // Messages read from the delivery stream at a time
+	streamBlock      = 5 * time.Second  // How long a read waits for new messages
+	streamStaleAfter = time.Minute      // Unacknowledged messages idle this long are taken over
+	streamClaimEvery = 30 * time.Second // How often stale messages are looked for
+)
+
+// ConsumeDeliveries sends deliveries as soon as they are announced on the delivery stream,
+// until ctx is done. Messages are acknowledged once their deliveries are handled; messages
+// left unacknowledged, e.g. by a worker that crashed, are taken over after a minute. The
+// deliveries themselves are claimed in Postgres like Notify does, so a message handled twice
+// never sends twice.
+func (n *notifierUseCase) ConsumeDeliveries(ctx context.Context, consumer queue.Consumer) error {
+	const op = "NotifierUseCase.ConsumeDeliveries"
+	logger.L().Sugar().Infof("%s: consuming delivery stream as %s", op, n.claims.WorkerID)
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= streamClaimEvery {
+			lastClaim = time.Now()
+			stale, err := consumer.ClaimStale(ctx, streamStaleAfter, streamReadCount)
+			if err != nil {
+				logger.L().Sugar().Warnf("%s: failed to claim stale messages: %v", op, err)
+			} else if len(stale) > 0 {
+				logger.L().Sugar().Infof("%s: took over %d stale messages", op, len(stale))
+				n.handleMessages(ctx, consumer, stale)
+			}
+		}
+
+		messages, err := consumer.Read(ctx, streamReadCount, streamBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.L().Sugar().Errorf("%s: failed to read delivery stream: %v", op, err)
+			select {
+			case <-time.After(streamBlock):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		n.handleMessages(ctx, consumer, messages)
+	}
+
+	logger.L().Sugar().Infof("%s: stopped consuming delivery stream", op)
+	return nil
+}
+
+// handleMessages sends the deliveries announced by messages that are still waiting to be sent
+// and acknowledges the messages. If the deliveries cannot be claimed, the messages stay
+// unacknowledged and are retried once they go stale.
+func (n *notifierUseCase) handleMessages(ctx context.Context, consumer queue.Consumer, messages []queue.Message) {
+	const op = "NotifierUseCase.handleMessages"
+
+	if len(messages) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(messages))
+	entries := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.DeliveryID
+		entries[i] = message.ID
+	}
+
+	deliveries, err := n.deliveryStore.ClaimDeliveriesByID(ctx, n.claims.WorkerID, ids, n.claims.Lease)
+	if err != nil {
+		logger.L().Sugar().Errorf("%s: failed to claim %d announced deliveries: %v", op, len(ids), err)
+		return
+	}
+	if len(deliveries) > 0 {
+		logger.L().Sugar().Infof("%s: claimed %d of %d announced deliveries as %s", op, len(deliveries), len(ids), n.claims.WorkerID)
+		if err := n.notifyBatch(ctx, deliveries); err != nil {
+			logger.L().Sugar().Errorf("%s: failed to send announced deliveries: %v", op, err)
+			return
+		}
+	}
+
+	if err := consumer.Ack(ctx, entries...); err != nil {
+		logger.L().Sugar().Warnf("%s: %v", op, err)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/notify"
 	"github.com/mackb/releaseradar/internal/adapter/persistence"
+	"github.com/mackb/releaseradar/internal/adapter/webhook"
 	"github.com/mackb/releaseradar/internal/domain"
 	"github.com/mackb/releaseradar/pkg/logger"
 )
@@ -15,14 +17,20 @@ type subscriptionUseCase struct {
 	subscriptionStore persistence.SubscriptionRepository
 	userStore         persistence.UserRepository
 	repoStore         persistence.RepoRepository
+	releaseStore      persistence.ReleaseRepository
+	channels          *notify.Registry
+	messages          *messageBuilder
 	transactor        persistence.Transactor
 }
 
-func NewSubscriptionUseCase(subscriptionStore persistence.SubscriptionRepository, userStore persistence.UserRepository, repoStore persistence.RepoRepository, transactor persistence.Transactor) SubscriptionUseCase {
+func NewSubscriptionUseCase(subscriptionStore persistence.SubscriptionRepository, userStore persistence.UserRepository, repoStore persistence.RepoRepository, releaseStore persistence.ReleaseRepository, channels *notify.Registry, transactor persistence.Transactor) SubscriptionUseCase {
 	return &subscriptionUseCase{
 		subscriptionStore: subscriptionStore,
 		userStore:         userStore,
 		repoStore:         repoStore,
+		releaseStore:      releaseStore,
+		channels:          channels,
+		messages:          newMessageBuilder(releaseStore),
 		transactor:        transactor,
 	}
 }
@@ -31,8 +39,15 @@ func (s *subscriptionUseCase) Subscribe(ctx context.Context, userID, repoID uuid
 	const op = "SubscriptionUseCase.Subscribe"
 	logger.L().Sugar().Debugf("%s: attempting to subscribe user %s to repo %s on channel %s", op, userID, repoID, channel)
 
+	// Reject targets no registered notifier can deliver to, and store the canonical "<type>:<address>" form
+	target, err := s.channels.Validate(channel)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	channel = target.String()
+
 	var subscription *domain.Subscription
-	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+	err = s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
 		_, err := s.userStore.GetUserByID(txCtx, userID)
 		if err != nil {
 			return fmt.Errorf("%s: user %s not found: %w", op, userID, err)
@@ -47,17 +62,41 @@ func (s *subscriptionUseCase) Subscribe(ctx context.Context, userID, repoID uuid
 		if err != nil && err != persistence.ErrNotFound {
 			return fmt.Errorf("%s: failed to check for existing subscription: %w", op, err)
 		}
+		if existingSub != nil && existingSub.DisabledAt != nil {
+			// Subscribing again after the channel failed re-enables the subscription with its settings
+			existingSub.DisabledAt = nil
+			existingSub.DisabledReason = ""
+			existingSub.UpdatedAt = time.Now()
+			if err := s.subscriptionStore.UpdateSubscription(txCtx, existingSub); err != nil {
+				return fmt.Errorf("%s: failed to re-enable subscription: %w", op, err)
+			}
+			// The receiver already has the webhook secret; it is only handed out on creation
+			reenabled := *existingSub
+			reenabled.Secret = ""
+			subscription = &reenabled
+			return nil
+		}
 		if existingSub != nil {
-			return fmt.Errorf("%s: user %s already subscribed to repo %s on channel %s", op, userID, repoID, channel)
+			return fmt.Errorf("%s: user %s already subscribed to repo %s on channel %s: %w", op, userID, repoID, channel, ErrAlreadyExists)
 		}
 
 		newSub := &domain.Subscription{
-			ID:        uuid.New(),
-			RepoID:    repoID,
-			UserID:    userID,
-			Channel:   channel,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			ID:            uuid.New(),
+			RepoID:        repoID,
+			UserID:        userID,
+			Channel:       channel,
+			DeliveryMode:  domain.DeliveryModeInstant,
+			DigestTime:    domain.DefaultDigestTime,
+			DigestWeekday: int(time.Monday),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		// Webhook payloads are signed; the secret is returned once, with the new subscription, for the receiver to verify
+		if target.Type == domain.ChannelWebhook {
+			newSub.Secret, err = webhook.NewSecret()
+			if err != nil {
+				return fmt.Errorf("%s: failed to generate webhook secret: %w", op, err)
+			}
 		}
 		if err := s.subscriptionStore.CreateSubscription(txCtx, newSub); err != nil {
 			return fmt.Errorf("%s: failed to create subscription: %w", op, err)
@@ -77,6 +116,7 @@ func (s *subscriptionUseCase) Subscribe(ctx context.Context, userID, repoID uuid
 func (s *subscriptionUseCase) Unsubscribe(ctx context.Context, userID, repoID uuid.UUID, channel string) error {
 	const op = "SubscriptionUseCase.Unsubscribe"
 	logger.L().Sugar().Debugf("%s: attempting to unsubscribe user %s from repo %s on channel %s", op, userID, repoID, channel)
+	channel = canonicalChannel(channel)
 
 	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
 		existingSub, err := s.subscriptionStore.GetSubscription(txCtx, repoID, userID, channel)
@@ -84,7 +124,7 @@ func (s *subscriptionUseCase) Unsubscribe(ctx context.Context, userID, repoID uu
 			return fmt.Errorf("%s: failed to check for existing subscription: %w", op, err)
 		}
 		if existingSub == nil {
-			return fmt.Errorf("%s: subscription not found for user %s, repo %s, channel %s", op, userID, repoID, channel)
+			return fmt.Errorf("%s: subscription for user %s, repo %s, channel %s: %w", op, userID, repoID, channel, persistence.ErrNotFound)
 		}
 
 		if err := s.subscriptionStore.DeleteSubscription(txCtx, repoID, userID, channel); err != nil {
@@ -101,6 +141,319 @@ func (s *subscriptionUseCase) Unsubscribe(ctx context.Context, userID, repoID uu
 	return nil
 }
 
+// UnsubscribeByID removes a subscription identified only by its ID, as carried in signed
+// unsubscribe links. It returns the removed subscription.
+func (s *subscriptionUseCase) UnsubscribeByID(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.UnsubscribeByID"
+	logger.L().Sugar().Debugf("%s: attempting to remove subscription %s", op, subscriptionID)
+
+	var subscription *domain.Subscription
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingSub, err := s.subscriptionStore.GetSubscriptionByID(txCtx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get subscription: %w", op, err)
+		}
+		if existingSub == nil {
+			return fmt.Errorf("%s: subscription %s: %w", op, subscriptionID, persistence.ErrNotFound)
+		}
+
+		if err := s.subscriptionStore.DeleteSubscriptionByID(txCtx, subscriptionID); err != nil {
+			return fmt.Errorf("%s: failed to delete subscription: %w", op, err)
+		}
+		subscription = existingSub
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Sugar().Infof("%s: removed subscription %s of user %s on channel %s", op, subscriptionID, subscription.UserID, subscription.Channel)
+	return subscription, nil
+}
+
+func (s *subscriptionUseCase) SetChangeNotifications(ctx context.Context, userID, repoID uuid.UUID, channel string, onUpdate, onDelete bool) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetChangeNotifications"
+	logger.L().Sugar().Debugf("%s: setting change notifications for user %s, repo %s, channel %s (update: %t, delete: %t)", op, userID, repoID, channel, onUpdate, onDelete)
+	channel = canonicalChannel(channel)
+
+	var subscription *domain.Subscription
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingSub, err := s.subscriptionStore.GetSubscription(txCtx, repoID, userID, channel)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get subscription: %w", op, err)
+		}
+		if existingSub == nil {
+			return fmt.Errorf("%s: subscription for user %s, repo %s, channel %s: %w", op, userID, repoID, channel, persistence.ErrNotFound)
+		}
+
+		existingSub.NotifyOnUpdate = onUpdate
+		existingSub.NotifyOnDelete = onDelete
+		existingSub.UpdatedAt = time.Now()
+		if err := s.subscriptionStore.UpdateSubscription(txCtx, existingSub); err != nil {
+			return fmt.Errorf("%s: failed to update subscription: %w", op, err)
+		}
+		subscription = existingSub
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// SetIncludeAssets sets whether notifications of the subscription list release assets.
+func (s *subscriptionUseCase) SetIncludeAssets(ctx context.Context, userID, repoID uuid.UUID, channel string, includeAssets bool) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetIncludeAssets"
+	logger.L().Sugar().Debugf("%s: setting asset listing for user %s, repo %s, channel %s to %t", op, userID, repoID, channel, includeAssets)
+	channel = canonicalChannel(channel)
+
+	var subscription *domain.Subscription
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingSub, err := s.subscriptionStore.GetSubscription(txCtx, repoID, userID, channel)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get subscription: %w", op, err)
+		}
+		if existingSub == nil {
+			return fmt.Errorf("%s: subscription for user %s, repo %s, channel %s: %w", op, userID, repoID, channel, persistence.ErrNotFound)
+		}
+
+		existingSub.IncludeAssets = includeAssets
+		existingSub.UpdatedAt = time.Now()
+		if err := s.subscriptionStore.UpdateSubscription(txCtx, existingSub); err != nil {
+			return fmt.Errorf("%s: failed to update subscription: %w", op, err)
+		}
+		subscription = existingSub
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription returns a subscription by ID, or persistence.ErrNotFound.
+func (s *subscriptionUseCase) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.GetSubscription"
+	logger.L().Sugar().Debugf("%s: attempting to get subscription %s", op, subscriptionID)
+
+	sub, err := s.subscriptionStore.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get subscription: %w", op, err)
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("%s: subscription %s: %w", op, subscriptionID, persistence.ErrNotFound)
+	}
+	return sub, nil
+}
+
+// Mute stops notifications of a subscription until the given time.
+func (s *subscriptionUseCase) Mute(ctx context.Context, subscriptionID uuid.UUID, until time.Time) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.Mute"
+	logger.L().Sugar().Debugf("%s: muting subscription %s until %s", op, subscriptionID, until)
+
+	subscription, err := s.updateByID(ctx, subscriptionID, func(sub *domain.Subscription) {
+		sub.MutedUntil = &until
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	logger.L().Sugar().Infof("%s: muted subscription %s until %s", op, subscriptionID, until)
+	return subscription, nil
+}
+
+// SetMajorOnly sets whether a subscription is only notified about major releases.
+func (s *subscriptionUseCase) SetMajorOnly(ctx context.Context, subscriptionID uuid.UUID, majorOnly bool) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetMajorOnly"
+	logger.L().Sugar().Debugf("%s: setting major-only of subscription %s to %t", op, subscriptionID, majorOnly)
+
+	subscription, err := s.updateByID(ctx, subscriptionID, func(sub *domain.Subscription) {
+		sub.MajorOnly = majorOnly
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return subscription, nil
+}
+
+// SetRepoTopics sets whether a Telegram forum subscription posts each repo in a topic of its own.
+func (s *subscriptionUseCase) SetRepoTopics(ctx context.Context, subscriptionID uuid.UUID, repoTopics bool) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetRepoTopics"
+	logger.L().Sugar().Debugf("%s: setting repo topics of subscription %s to %t", op, subscriptionID, repoTopics)
+
+	subscription, err := s.updateByID(ctx, subscriptionID, func(sub *domain.Subscription) {
+		sub.RepoTopics = repoTopics
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return subscription, nil
+}
+
+// SetDeliveryMode sets whether a subscription notifies instantly or in hourly, daily or weekly
+// digests. digestTime ("HH:MM", in the user's timezone) and digestWeekday (0 is Sunday) set
+// when daily and weekly digests go out; an empty digestTime is DefaultDigestTime.
+func (s *subscriptionUseCase) SetDeliveryMode(ctx context.Context, subscriptionID uuid.UUID, mode, digestTime string, digestWeekday int) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetDeliveryMode"
+	logger.L().Sugar().Debugf("%s: setting delivery mode of subscription %s to %s", op, subscriptionID, mode)
+
+	if digestTime == "" {
+		digestTime = domain.DefaultDigestTime
+	}
+	if err := domain.ValidateDeliveryMode(mode, digestTime, digestWeekday); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidArgument, err)
+	}
+
+	existingSub, err := s.subscriptionStore.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get subscription: %w", op, err)
+	}
+	if existingSub == nil {
+		return nil, fmt.Errorf("%s: subscription %s: %w", op, subscriptionID, persistence.ErrNotFound)
+	}
+	// Webhook payloads describe one release each, and receivers are programs with no use for digests
+	if target, err := domain.ParseChannel(existingSub.Channel); err == nil && target.Type == domain.ChannelWebhook && mode != domain.DeliveryModeInstant {
+		return nil, fmt.Errorf("%s: %w: webhook channels only support instant delivery", op, ErrInvalidArgument)
+	}
+
+	subscription, err := s.updateByID(ctx, subscriptionID, func(sub *domain.Subscription) {
+		sub.DeliveryMode = mode
+		sub.DigestTime = digestTime
+		sub.DigestWeekday = digestWeekday
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	logger.L().Sugar().Infof("%s: set delivery mode of subscription %s to %s", op, subscriptionID, mode)
+	return subscription, nil
+}
+
+// updateByID applies change to a stored subscription within a transaction.
+func (s *subscriptionUseCase) updateByID(ctx context.Context, subscriptionID uuid.UUID, change func(*domain.Subscription)) (*domain.Subscription, error) {
+	var subscription *domain.Subscription
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingSub, err := s.subscriptionStore.GetSubscriptionByID(txCtx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("failed to get subscription: %w", err)
+		}
+		if existingSub == nil {
+			return fmt.Errorf("subscription %s: %w", subscriptionID, persistence.ErrNotFound)
+		}
+
+		change(existingSub)
+		existingSub.UpdatedAt = time.Now()
+		if err := s.subscriptionStore.UpdateSubscription(txCtx, existingSub); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+		subscription = existingSub
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// SetTemplate validates and stores a custom message template. An empty template restores the
+// channel type's default.
+func (s *subscriptionUseCase) SetTemplate(ctx context.Context, subscriptionID uuid.UUID, template string) (*domain.Subscription, error) {
+	const op = "SubscriptionUseCase.SetTemplate"
+	logger.L().Sugar().Debugf("%s: setting template of subscription %s", op, subscriptionID)
+
+	var subscription *domain.Subscription
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingSub, err := s.subscriptionStore.GetSubscriptionByID(txCtx, subscriptionID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get subscription: %w", op, err)
+		}
+		if existingSub == nil {
+			return fmt.Errorf("%s: subscription %s: %w", op, subscriptionID, persistence.ErrNotFound)
+		}
+
+		if template != "" {
+			target, err := domain.ParseChannel(existingSub.Channel)
+			if err != nil {
+				return fmt.Errorf("%s: %w: %v", op, notify.ErrInvalidChannel, err)
+			}
+			if _, err := notify.ParseTemplate(target.Type, template); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		existingSub.Template = template
+		existingSub.UpdatedAt = time.Now()
+		if err := s.subscriptionStore.UpdateSubscription(txCtx, existingSub); err != nil {
+			return fmt.Errorf("%s: failed to update subscription: %w", op, err)
+		}
+		subscription = existingSub
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Sugar().Infof("%s: updated template of subscription %s", op, subscriptionID)
+	return subscription, nil
+}
+
+// PreviewTemplate renders a new-release notification for a stored release as the subscription
+// would receive it. A non-nil template is validated and previewed instead of the stored one.
+func (s *subscriptionUseCase) PreviewTemplate(ctx context.Context, subscriptionID, releaseID uuid.UUID, template *string) (string, error) {
+	const op = "SubscriptionUseCase.PreviewTemplate"
+	logger.L().Sugar().Debugf("%s: previewing template of subscription %s with release %s", op, subscriptionID, releaseID)
+
+	sub, err := s.subscriptionStore.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get subscription: %w", op, err)
+	}
+	if sub == nil {
+		return "", fmt.Errorf("%s: subscription %s: %w", op, subscriptionID, persistence.ErrNotFound)
+	}
+
+	release, err := s.releaseStore.GetReleaseByID(ctx, releaseID)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get release: %w", op, err)
+	}
+	if release == nil {
+		return "", fmt.Errorf("%s: release %s: %w", op, releaseID, persistence.ErrNotFound)
+	}
+	repo, err := s.repoStore.GetRepoByID(ctx, release.RepoID)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to get repo: %w", op, err)
+	}
+	if repo == nil {
+		return "", fmt.Errorf("%s: repo %s: %w", op, release.RepoID, persistence.ErrNotFound)
+	}
+
+	if template != nil {
+		target, err := domain.ParseChannel(sub.Channel)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w: %v", op, notify.ErrInvalidChannel, err)
+		}
+		if *template != "" {
+			if _, err := notify.ParseTemplate(target.Type, *template); err != nil {
+				return "", fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		preview := *sub
+		preview.Template = *template
+		sub = &preview
+	}
+
+	msg, err := s.messages.build(ctx, domain.DeliveryKindRelease, sub.Channel, repo, release, nil, sub)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to render preview: %w", op, err)
+	}
+	return msg.Text, nil
+}
+
 func (s *subscriptionUseCase) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error) {
 	const op = "SubscriptionUseCase.ListSubscriptions"
 	logger.L().Sugar().Debugf("%s: attempting to list subscriptions for user %s", op, userID)
@@ -112,3 +465,13 @@ func (s *subscriptionUseCase) ListSubscriptions(ctx context.Context, userID uuid
 
 	return subs, nil
 }
+
+// canonicalChannel returns the stored form of a channel, leaving unparsable input untouched
+// so that lookups simply find nothing.
+func canonicalChannel(channel string) string {
+	target, err := domain.ParseChannel(channel)
+	if err != nil {
+		return channel
+	}
+	return target.String()
+}
@@ -2,35 +2,68 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/mackb/releaseradar/internal/adapter/queue"
 	"github.com/mackb/releaseradar/internal/domain"
 )
 
 type UserUseCase interface {
 	SignUp(ctx context.Context, email string) (*domain.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	GetOrCreateTelegramUser(ctx context.Context, chatID int64) (*domain.User, error)
+	SetTimezone(ctx context.Context, userID uuid.UUID, timezone string) (*domain.User, error)
+	SetQuietHours(ctx context.Context, quiet *domain.QuietHours) (*domain.QuietHours, error)
+	ClearQuietHours(ctx context.Context, userID uuid.UUID, channel string) error
+	ListQuietHours(ctx context.Context, userID uuid.UUID) ([]domain.QuietHours, error)
 }
 
 type RepoUseCase interface {
 	AddRepo(ctx context.Context, userID uuid.UUID, owner, name string) (*domain.Repo, error)
 	ListRepos(ctx context.Context, userID uuid.UUID) ([]domain.Repo, error)
 	GetRepoByID(ctx context.Context, repoID uuid.UUID) (*domain.Repo, error)
+	GetRepoByName(ctx context.Context, owner, name string) (*domain.Repo, error)
+	FindOrAddRepo(ctx context.Context, userID uuid.UUID, owner, name string) (*domain.Repo, error)
 }
 
 type SubscriptionUseCase interface {
 	Subscribe(ctx context.Context, userID, repoID uuid.UUID, channel string) (*domain.Subscription, error)
 	Unsubscribe(ctx context.Context, userID, repoID uuid.UUID, channel string) error
+	UnsubscribeByID(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error)
+	SetChangeNotifications(ctx context.Context, userID, repoID uuid.UUID, channel string, onUpdate, onDelete bool) (*domain.Subscription, error)
+	SetIncludeAssets(ctx context.Context, userID, repoID uuid.UUID, channel string, includeAssets bool) (*domain.Subscription, error)
 	ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error)
+	GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error)
+	Mute(ctx context.Context, subscriptionID uuid.UUID, until time.Time) (*domain.Subscription, error)
+	SetMajorOnly(ctx context.Context, subscriptionID uuid.UUID, majorOnly bool) (*domain.Subscription, error)
+	SetRepoTopics(ctx context.Context, subscriptionID uuid.UUID, repoTopics bool) (*domain.Subscription, error)
+	SetDeliveryMode(ctx context.Context, subscriptionID uuid.UUID, mode, digestTime string, digestWeekday int) (*domain.Subscription, error)
+	SetTemplate(ctx context.Context, subscriptionID uuid.UUID, template string) (*domain.Subscription, error)
+	PreviewTemplate(ctx context.Context, subscriptionID, releaseID uuid.UUID, template *string) (string, error)
+}
+
+type ReleaseUseCase interface {
+	ListReleaseAssets(ctx context.Context, releaseID uuid.UUID) ([]domain.ReleaseAsset, error)
+	GetRelease(ctx context.Context, releaseID uuid.UUID) (*domain.Release, error)
 }
 
 type PollerUseCase interface {
 	PollReleases(ctx context.Context) error
 	EnqueueDeliveries(ctx context.Context, release *domain.Release) error
+	ReconcileDeliveries(ctx context.Context) error
 }
 
 type NotifierUseCase interface {
 	Notify(ctx context.Context) error
+	SendDigests(ctx context.Context) error
+	ReleaseDeferred(ctx context.Context) error
+	ConsumeDeliveries(ctx context.Context, consumer queue.Consumer) error
+}
+
+type DeliveryUseCase interface {
+	ListDeadDeliveries(ctx context.Context, userID *uuid.UUID, limit int) ([]domain.Delivery, error)
+	RetryDelivery(ctx context.Context, deliveryID uuid.UUID) (*domain.Delivery, error)
 }
 
 // Usecases combines all use case interfaces
@@ -38,6 +71,7 @@ type Usecases struct { // Удалены неиспользуемые поля
 	User         UserUseCase
 	Repo         RepoUseCase
 	Subscription SubscriptionUseCase
+	Release      ReleaseUseCase
 	Poller       PollerUseCase
 	Notifier     NotifierUseCase
 }
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,12 +13,13 @@ import (
 )
 
 type userUseCase struct {
-	repo  persistence.UserRepository
-	store persistence.Transactor
+	repo       persistence.UserRepository
+	quietStore persistence.QuietHoursRepository
+	store      persistence.Transactor
 }
 
-func NewUserUseCase(repo persistence.UserRepository, store persistence.Transactor) UserUseCase {
-	return &userUseCase{repo: repo, store: store}
+func NewUserUseCase(repo persistence.UserRepository, quietStore persistence.QuietHoursRepository, store persistence.Transactor) UserUseCase {
+	return &userUseCase{repo: repo, quietStore: quietStore, store: store}
 }
 
 func (u *userUseCase) SignUp(ctx context.Context, email string) (*domain.User, error) {
@@ -66,3 +68,165 @@ func (u *userUseCase) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.Us
 
 	return user, nil
 }
+
+// GetOrCreateTelegramUser returns the user of a Telegram chat, creating it on first contact
+// with the bot. Each private chat, group or channel is a user of its own.
+func (u *userUseCase) GetOrCreateTelegramUser(ctx context.Context, chatID int64) (*domain.User, error) {
+	const op = "UserUseCase.GetOrCreateTelegramUser"
+	logger.L().Sugar().Debugf("%s: attempting to get user of telegram chat %d", op, chatID)
+
+	var user *domain.User
+	created := false
+	err := u.store.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingUser, err := u.repo.GetUserByTelegramChatID(txCtx, chatID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get user by telegram chat: %w", op, err)
+		}
+		if existingUser != nil {
+			user = existingUser
+			return nil
+		}
+
+		newUser := &domain.User{
+			ID:             uuid.New(),
+			TelegramChatID: &chatID,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if err := u.repo.CreateUser(txCtx, newUser); err != nil {
+			return fmt.Errorf("%s: failed to create user: %w", op, err)
+		}
+		user = newUser
+		created = true
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		logger.L().Sugar().Infof("%s: created user %s for telegram chat %d", op, user.ID, chatID)
+	}
+	return user, nil
+}
+
+// SetTimezone sets the timezone digests of the user are scheduled in, as an IANA name such as
+// "Europe/Berlin". An empty timezone is UTC. Digests already scheduled keep their time.
+func (u *userUseCase) SetTimezone(ctx context.Context, userID uuid.UUID, timezone string) (*domain.User, error) {
+	const op = "UserUseCase.SetTimezone"
+	logger.L().Sugar().Debugf("%s: setting timezone of user %s to %q", op, userID, timezone)
+
+	if _, err := time.LoadLocation(timezone); err != nil || strings.EqualFold(timezone, "local") {
+		return nil, fmt.Errorf("%s: %w: unknown timezone %q", op, ErrInvalidArgument, timezone)
+	}
+
+	var user *domain.User
+	err := u.store.WithinTransaction(ctx, func(txCtx context.Context) error {
+		existingUser, err := u.repo.GetUserByID(txCtx, userID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get user: %w", op, err)
+		}
+		if existingUser == nil {
+			return fmt.Errorf("%s: user %s: %w", op, userID, persistence.ErrNotFound)
+		}
+
+		existingUser.Timezone = timezone
+		existingUser.UpdatedAt = time.Now()
+		if err := u.repo.UpdateUser(txCtx, existingUser); err != nil {
+			return fmt.Errorf("%s: failed to update user: %w", op, err)
+		}
+		user = existingUser
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Sugar().Infof("%s: set timezone of user %s to %q", op, userID, timezone)
+	return user, nil
+}
+
+// SetQuietHours stores the quiet hours of a user, for one channel or, with an empty channel,
+// for all channels without their own. Deliveries due inside the window are deferred to its end.
+func (u *userUseCase) SetQuietHours(ctx context.Context, quiet *domain.QuietHours) (*domain.QuietHours, error) {
+	const op = "UserUseCase.SetQuietHours"
+	logger.L().Sugar().Debugf("%s: setting quiet hours of user %s on channel %q to %s-%s", op, quiet.UserID, quiet.Channel, quiet.Start, quiet.End)
+
+	if err := domain.ValidateQuietHours(quiet.Start, quiet.End); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidArgument, err)
+	}
+	if quiet.Channel != "" {
+		target, err := domain.ParseChannel(quiet.Channel)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %v", op, ErrInvalidArgument, err)
+		}
+		quiet.Channel = target.String()
+	}
+
+	err := u.store.WithinTransaction(ctx, func(txCtx context.Context) error {
+		user, err := u.repo.GetUserByID(txCtx, quiet.UserID)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get user: %w", op, err)
+		}
+		if user == nil {
+			return fmt.Errorf("%s: user %s: %w", op, quiet.UserID, persistence.ErrNotFound)
+		}
+
+		now := time.Now()
+		quiet.CreatedAt = now
+		quiet.UpdatedAt = now
+		if err := u.quietStore.SaveQuietHours(txCtx, quiet); err != nil {
+			return fmt.Errorf("%s: failed to save quiet hours: %w", op, err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Sugar().Infof("%s: set quiet hours of user %s on channel %q to %s-%s", op, quiet.UserID, quiet.Channel, quiet.Start, quiet.End)
+	return quiet, nil
+}
+
+// ClearQuietHours removes the quiet hours of a user's channel, or the user's default ones for
+// an empty channel. Deliveries already deferred are still released when the window ends.
+func (u *userUseCase) ClearQuietHours(ctx context.Context, userID uuid.UUID, channel string) error {
+	const op = "UserUseCase.ClearQuietHours"
+	logger.L().Sugar().Debugf("%s: clearing quiet hours of user %s on channel %q", op, userID, channel)
+
+	if channel != "" {
+		target, err := domain.ParseChannel(channel)
+		if err != nil {
+			return fmt.Errorf("%s: %w: %v", op, ErrInvalidArgument, err)
+		}
+		channel = target.String()
+	}
+
+	quiet, err := u.quietStore.GetQuietHours(ctx, userID, channel)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get quiet hours: %w", op, err)
+	}
+	if quiet == nil {
+		return fmt.Errorf("%s: quiet hours of user %s on channel %q: %w", op, userID, channel, persistence.ErrNotFound)
+	}
+	if err := u.quietStore.DeleteQuietHours(ctx, userID, channel); err != nil {
+		return fmt.Errorf("%s: failed to delete quiet hours: %w", op, err)
+	}
+
+	logger.L().Sugar().Infof("%s: cleared quiet hours of user %s on channel %q", op, userID, channel)
+	return nil
+}
+
+func (u *userUseCase) ListQuietHours(ctx context.Context, userID uuid.UUID) ([]domain.QuietHours, error) {
+	const op = "UserUseCase.ListQuietHours"
+	logger.L().Sugar().Debugf("%s: listing quiet hours of user %s", op, userID)
+
+	quiet, err := u.quietStore.ListQuietHours(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list quiet hours: %w", op, err)
+	}
+	return quiet, nil
+}
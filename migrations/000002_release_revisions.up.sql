@@ -0,0 +1,36 @@
+-- Store release notes and track releases that disappear upstream
+ALTER TABLE releases
+    ADD COLUMN body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Create "release_revisions" table
+CREATE TABLE release_revisions (
+    id UUID PRIMARY KEY,
+    release_id UUID NOT NULL REFERENCES releases(id) ON DELETE CASCADE,
+    tag VARCHAR(255) NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL,
+    summary TEXT NOT NULL DEFAULT '', -- Diff against the previous revision
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX release_revisions_release_id_idx ON release_revisions (release_id, created_at);
+
+-- Opt-in flags for edit and retraction notifications
+ALTER TABLE subscriptions
+    ADD COLUMN notify_on_update BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN notify_on_delete BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A release can now produce several deliveries per subscriber (new, updated, deleted)
+ALTER TABLE deliveries
+    ADD COLUMN kind VARCHAR(50) NOT NULL DEFAULT 'release', -- e.g., "release", "release_updated", "release_deleted"
+    ADD COLUMN revision_id UUID REFERENCES release_revisions(id) ON DELETE CASCADE;
+
+ALTER TABLE deliveries DROP CONSTRAINT deliveries_release_id_user_id_channel_key;
+
+CREATE UNIQUE INDEX deliveries_event_key ON deliveries (
+    release_id, user_id, channel, kind,
+    COALESCE(revision_id, '00000000-0000-0000-0000-000000000000')
+);
@@ -0,0 +1,25 @@
+-- Track GitHub's numeric repo ID (stable across renames/transfers) and repo availability
+ALTER TABLE repos
+    ADD COLUMN github_id BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN not_found_count INT NOT NULL DEFAULT 0, -- Consecutive 404s from GitHub
+    ADD COLUMN deactivated_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX repos_github_id_key ON repos (github_id) WHERE github_id <> 0;
+
+-- Deliveries can now be repo-level notices without a release
+ALTER TABLE deliveries ADD COLUMN repo_id UUID REFERENCES repos(id) ON DELETE CASCADE;
+
+UPDATE deliveries d SET repo_id = r.repo_id FROM releases r WHERE r.id = d.release_id;
+
+ALTER TABLE deliveries
+    ALTER COLUMN repo_id SET NOT NULL,
+    ALTER COLUMN release_id DROP NOT NULL;
+
+DROP INDEX deliveries_event_key;
+
+CREATE UNIQUE INDEX deliveries_event_key ON deliveries (
+    repo_id, user_id, channel, kind,
+    COALESCE(release_id, '00000000-0000-0000-0000-000000000000'),
+    COALESCE(revision_id, '00000000-0000-0000-0000-000000000000')
+);
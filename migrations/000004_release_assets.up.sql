@@ -0,0 +1,17 @@
+-- Create "release_assets" table
+CREATE TABLE release_assets (
+    id UUID PRIMARY KEY,
+    release_id UUID NOT NULL REFERENCES releases(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    download_url TEXT NOT NULL,
+    download_count INT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64) NOT NULL DEFAULT '', -- From the release's checksums file, if any
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (release_id, name)
+);
+
+-- Opt-in flag for listing assets in notifications
+ALTER TABLE subscriptions ADD COLUMN include_assets BOOLEAN NOT NULL DEFAULT FALSE;
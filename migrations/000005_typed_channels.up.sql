@@ -0,0 +1,6 @@
+-- Channels are now typed ("<type>:<address>"); untyped values are legacy Telegram chat IDs
+UPDATE subscriptions SET channel = 'telegram:' || channel WHERE channel NOT LIKE '%:%';
+UPDATE deliveries SET channel = 'telegram:' || channel WHERE channel NOT LIKE '%:%';
+
+ALTER TABLE subscriptions ALTER COLUMN channel TYPE TEXT; -- Webhook URLs can exceed 255 characters
+ALTER TABLE deliveries ALTER COLUMN channel TYPE TEXT;
@@ -0,0 +1,2 @@
+-- GitHub's prerelease flag, used to classify releases
+ALTER TABLE releases ADD COLUMN prerelease BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Per-subscription secret used to sign outgoing webhook payloads
+ALTER TABLE subscriptions ADD COLUMN secret TEXT NOT NULL DEFAULT '';
+
+-- HTTP status returned by the destination on the latest attempt (0 when not applicable)
+ALTER TABLE deliveries ADD COLUMN response_code INTEGER NOT NULL DEFAULT 0;
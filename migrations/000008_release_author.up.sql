@@ -0,0 +1,2 @@
+-- GitHub login of the account that published the release
+ALTER TABLE releases ADD COLUMN author TEXT NOT NULL DEFAULT '';
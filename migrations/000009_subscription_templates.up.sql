@@ -0,0 +1,2 @@
+-- Custom message template; empty uses the channel type's default
+ALTER TABLE subscriptions ADD COLUMN template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Subscriptions are disabled, not removed, when their channel stops accepting messages
+ALTER TABLE subscriptions ADD COLUMN disabled_at TIMESTAMPTZ;
+ALTER TABLE subscriptions ADD COLUMN disabled_reason TEXT NOT NULL DEFAULT '';
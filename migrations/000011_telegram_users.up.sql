@@ -0,0 +1,5 @@
+-- Users created from a Telegram chat (bot /start) are identified by the chat and have no email
+ALTER TABLE users ADD COLUMN telegram_chat_id BIGINT UNIQUE;
+
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_key ON users (email) WHERE email <> '';
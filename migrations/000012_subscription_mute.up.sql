@@ -0,0 +1,3 @@
+-- Set from the buttons on Telegram notifications
+ALTER TABLE subscriptions ADD COLUMN muted_until TIMESTAMPTZ;
+ALTER TABLE subscriptions ADD COLUMN major_only BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- Subscriptions to Telegram forums can post each repo in a topic of its own
+ALTER TABLE subscriptions ADD COLUMN repo_topics BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Forum topic created for a repo in a chat
+CREATE TABLE telegram_topics (
+    chat_id TEXT NOT NULL,
+    repo_id UUID NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+    thread_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, repo_id)
+);
@@ -0,0 +1,11 @@
+-- Digest schedules are in the user's timezone (IANA name; empty is UTC)
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
+
+-- Subscriptions can collect deliveries into hourly, daily or weekly digests
+ALTER TABLE subscriptions ADD COLUMN delivery_mode TEXT NOT NULL DEFAULT 'instant';
+ALTER TABLE subscriptions ADD COLUMN digest_time TEXT NOT NULL DEFAULT '09:00';
+ALTER TABLE subscriptions ADD COLUMN digest_weekday SMALLINT NOT NULL DEFAULT 1;
+
+-- Deliveries waiting for their digest have status 'digest' until scheduled_at
+ALTER TABLE deliveries ADD COLUMN scheduled_at TIMESTAMPTZ;
+CREATE INDEX deliveries_digest_due_idx ON deliveries (scheduled_at) WHERE status = 'digest';
@@ -0,0 +1,15 @@
+-- Daily windows in which deliveries are held back; channel '' is the user's default
+CREATE TABLE quiet_hours (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL DEFAULT '',
+    start TEXT NOT NULL,
+    "end" TEXT NOT NULL,
+    bundle BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_security BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, channel)
+);
+
+-- Deliveries held by quiet hours have status 'deferred' until scheduled_at
+CREATE INDEX deliveries_deferred_due_idx ON deliveries (scheduled_at) WHERE status = 'deferred';
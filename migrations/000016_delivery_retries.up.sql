@@ -0,0 +1,8 @@
+-- Failed deliveries are retried at next_attempt_at with exponential backoff, and become
+-- 'dead' once they run out of attempts
+ALTER TABLE deliveries ADD COLUMN next_attempt_at TIMESTAMPTZ;
+CREATE INDEX deliveries_retry_due_idx ON deliveries (next_attempt_at) WHERE status = 'failed';
+CREATE INDEX deliveries_dead_idx ON deliveries (updated_at) WHERE status = 'dead';
+
+-- Deliveries that failed before retries existed get another go
+UPDATE deliveries SET next_attempt_at = NOW() WHERE status = 'failed';
@@ -0,0 +1,3 @@
+-- The delivery reconciler looks for recent releases without 'release' deliveries
+CREATE INDEX releases_created_at_idx ON releases (created_at);
+CREATE INDEX deliveries_release_id_idx ON deliveries (release_id) WHERE kind = 'release';
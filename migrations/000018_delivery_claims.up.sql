@@ -0,0 +1,4 @@
+-- Workers claim deliveries before sending them; a claim whose lease expired can be taken over
+ALTER TABLE deliveries ADD COLUMN claimed_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN claimed_until TIMESTAMPTZ;
+CREATE INDEX deliveries_pending_idx ON deliveries (created_at) WHERE status = 'pending';
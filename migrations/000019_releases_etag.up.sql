@@ -0,0 +1,2 @@
+-- ETag of the release list, so unchanged lists are not re-read on every poll
+ALTER TABLE repos ADD COLUMN releases_etag VARCHAR(255) NOT NULL DEFAULT '';
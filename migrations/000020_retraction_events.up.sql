@@ -0,0 +1,12 @@
+-- Retractions have no revision, so a release restored and retracted again collided with its
+-- first retraction and was silently skipped; key them by when the release was retracted
+ALTER TABLE deliveries ADD COLUMN retracted_at TIMESTAMPTZ;
+
+DROP INDEX deliveries_event_key;
+
+CREATE UNIQUE INDEX deliveries_event_key ON deliveries (
+    repo_id, user_id, channel, kind,
+    COALESCE(release_id, '00000000-0000-0000-0000-000000000000'),
+    COALESCE(revision_id, '00000000-0000-0000-0000-000000000000'),
+    COALESCE(retracted_at, TIMESTAMPTZ '1970-01-01 00:00:00+00')
+);
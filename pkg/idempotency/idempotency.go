@@ -2,12 +2,39 @@ package idempotency
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
+// ErrInProgress is returned by DoResult when another caller holds the lease on the key.
+var ErrInProgress = errors.New("operation in progress")
+
+// ErrLeaseLost is returned by Storage.Complete and Storage.Release when the lease expired and
+// the key is no longer held with the token.
+var ErrLeaseLost = errors.New("idempotency lease lost")
+
+// Lease is the outcome of Storage.Acquire.
+type Lease struct {
+	Token     string // Identifies the lease when it was acquired, empty otherwise
+	Completed bool   // The operation already completed; Result holds what it stored
+	Result    []byte
+}
+
+// Acquired reports whether the caller got the lease and should run the operation.
+func (l Lease) Acquired() bool {
+	return l.Token != ""
+}
+
+// Storage keeps the state of idempotent operations: an "in progress" lease that expires, and
+// the stored result once the operation completed.
 type Storage interface {
-	CheckAndSet(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	// Acquire takes a lease on key for ttl, unless the key is leased or completed already.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error)
+	// Complete marks the leased key as completed with result, kept for ttl.
+	Complete(ctx context.Context, key, token string, result []byte, ttl time.Duration) error
+	// Release drops the lease so that the operation can be tried again.
+	Release(ctx context.Context, key, token string) error
 }
 
 type Manager struct {
@@ -18,16 +45,44 @@ func NewManager(storage Storage) *Manager {
 	return &Manager{storage: storage}
 }
 
+// Do runs fn unless the operation is in progress elsewhere or completed within expiration.
+// The lease is released if fn fails, so a later call runs fn again.
 func (m *Manager) Do(ctx context.Context, key string, expiration time.Duration, fn func() error) error {
-	set, err := m.storage.CheckAndSet(ctx, key, expiration)
+	_, err := m.DoResult(ctx, key, expiration, func() ([]byte, error) {
+		return nil, fn()
+	})
+	if errors.Is(err, ErrInProgress) {
+		// Another worker is on it
+		return nil
+	}
+	return err
+}
+
+// DoResult is Do for operations with a result: fn's result is stored on success and returned
+// to later calls instead of running fn again. It returns ErrInProgress while another caller
+// holds the lease.
+func (m *Manager) DoResult(ctx context.Context, key string, expiration time.Duration, fn func() ([]byte, error)) ([]byte, error) {
+	lease, err := m.storage.Acquire(ctx, key, expiration)
 	if err != nil {
-		return fmt.Errorf("idempotency check failed: %w", err)
+		return nil, fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if lease.Completed {
+		return lease.Result, nil
+	}
+	if !lease.Acquired() {
+		return nil, ErrInProgress
 	}
 
-	if !set {
-		// Operation already in progress or completed
-		return nil // Or return a specific idempotency error if needed
+	result, err := fn()
+	if err != nil {
+		if releaseErr := m.storage.Release(ctx, key, lease.Token); releaseErr != nil && !errors.Is(releaseErr, ErrLeaseLost) {
+			return nil, fmt.Errorf("%w (idempotency release failed: %v)", err, releaseErr)
+		}
+		return nil, err
 	}
 
-	return fn()
+	if err := m.storage.Complete(ctx, key, lease.Token, result, expiration); err != nil {
+		return result, fmt.Errorf("idempotency complete failed: %w", err)
+	}
+	return result, nil
 }
@@ -0,0 +1,172 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	token     string
+	completed bool
+	result    []byte
+	expires   time.Time
+}
+
+// memoryStorage keeps leases in a map with the semantics of the Redis storage, on a clock the
+// test moves forward.
+type memoryStorage struct {
+	now     time.Time
+	entries map[string]entry
+	tokens  int
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{now: time.Unix(0, 0), entries: map[string]entry{}}
+}
+
+func (s *memoryStorage) get(key string) (entry, bool) {
+	e, ok := s.entries[key]
+	if ok && !s.now.Before(e.expires) {
+		delete(s.entries, key)
+		return entry{}, false
+	}
+	return e, ok
+}
+
+func (s *memoryStorage) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	e, ok := s.get(key)
+	switch {
+	case !ok:
+		s.tokens++
+		token := strconv.Itoa(s.tokens)
+		s.entries[key] = entry{token: token, expires: s.now.Add(ttl)}
+		return Lease{Token: token}, nil
+	case e.completed:
+		return Lease{Completed: true, Result: e.result}, nil
+	}
+	return Lease{}, nil
+}
+
+func (s *memoryStorage) Complete(ctx context.Context, key, token string, result []byte, ttl time.Duration) error {
+	if e, ok := s.get(key); !ok || e.completed || e.token != token {
+		return ErrLeaseLost
+	}
+	s.entries[key] = entry{completed: true, result: result, expires: s.now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStorage) Release(ctx context.Context, key, token string) error {
+	if e, ok := s.get(key); !ok || e.completed || e.token != token {
+		return ErrLeaseLost
+	}
+	delete(s.entries, key)
+	return nil
+}
+
+func TestDoResultStoresResult(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryStorage()
+	m := NewManager(storage)
+
+	calls := 0
+	fn := func() ([]byte, error) {
+		calls++
+		return []byte("42"), nil
+	}
+	for i := 0; i < 2; i++ {
+		result, err := m.DoResult(ctx, "key", time.Minute, fn)
+		if err != nil || string(result) != "42" {
+			t.Fatalf("DoResult #%d = %q, %v, want 42", i+1, result, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fn ran %d times, want once", calls)
+	}
+
+	// The result is forgotten once it expires
+	storage.now = storage.now.Add(time.Minute)
+	if _, err := m.DoResult(ctx, "key", time.Minute, fn); err != nil {
+		t.Fatalf("DoResult after expiry: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("fn ran %d times, want twice after the result expired", calls)
+	}
+}
+
+func TestDoReleasesLeaseOnFailure(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(newMemoryStorage())
+	errSend := errors.New("send failed")
+
+	if err := m.Do(ctx, "key", time.Minute, func() error { return errSend }); !errors.Is(err, errSend) {
+		t.Fatalf("Do = %v, want %v", err, errSend)
+	}
+
+	ran := false
+	if err := m.Do(ctx, "key", time.Minute, func() error { ran = true; return nil }); err != nil {
+		t.Fatalf("Do retry: %v", err)
+	}
+	if !ran {
+		t.Error("the retry was skipped after a failure")
+	}
+}
+
+func TestLeaseInProgress(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryStorage()
+	m := NewManager(storage)
+
+	lease, err := storage.Acquire(ctx, "key", time.Minute)
+	if err != nil || !lease.Acquired() {
+		t.Fatalf("Acquire = %+v, %v, want a lease", lease, err)
+	}
+
+	ran := false
+	fn := func() ([]byte, error) { ran = true; return nil, nil }
+	if _, err := m.DoResult(ctx, "key", time.Minute, fn); !errors.Is(err, ErrInProgress) {
+		t.Errorf("DoResult = %v, want ErrInProgress", err)
+	}
+	if err := m.Do(ctx, "key", time.Minute, func() error { _, err := fn(); return err }); err != nil {
+		t.Errorf("Do = %v, want nil while another caller holds the lease", err)
+	}
+	if ran {
+		t.Error("fn ran while another caller held the lease")
+	}
+
+	// An abandoned lease expires and the operation can run again
+	storage.now = storage.now.Add(time.Minute)
+	if _, err := m.DoResult(ctx, "key", time.Minute, fn); err != nil || !ran {
+		t.Errorf("DoResult after the lease expired = %v, ran %v, want fn to run", err, ran)
+	}
+}
+
+func TestLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryStorage()
+	m := NewManager(storage)
+
+	// fn outlives its lease, and another caller takes the key in the meantime
+	_, err := m.DoResult(ctx, "key", time.Minute, func() ([]byte, error) {
+		storage.now = storage.now.Add(2 * time.Minute)
+		if _, err := storage.Acquire(ctx, "key", time.Minute); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		return []byte("late"), nil
+	})
+	if !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("DoResult = %v, want ErrLeaseLost", err)
+	}
+
+	// Losing the lease before a failure is not an error of its own
+	errSend := errors.New("send failed")
+	err = m.Do(ctx, "other", time.Minute, func() error {
+		storage.now = storage.now.Add(2 * time.Minute)
+		return errSend
+	})
+	if !errors.Is(err, errSend) || errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Do = %v, want only %v", err, errSend)
+	}
+}
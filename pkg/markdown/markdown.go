@@ -0,0 +1,430 @@
+// Package markdown converts GitHub-flavoured Markdown, as found in release notes, into the
+// small HTML subset Telegram accepts (b, i, code, pre, a) or into plain text.
+//
+// It is deliberately forgiving rather than CommonMark-complete: anything it does not
+// understand is shown as escaped text, so the output is always safe to send.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Segment is a run of text with uniform formatting. Rendering each segment with its own
+// opening and closing tags keeps the output balanced wherever it is truncated.
+type Segment struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Code   bool   // Inline code
+	Pre    bool   // Code block
+	Lang   string // Language of a code block, if given
+	Href   string // Link target
+}
+
+// Document is parsed Markdown.
+type Document []Segment
+
+var (
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	fencePattern   = regexp.MustCompile("^\\s{0,3}(```+|~~~+)\\s*([\\w+#.-]*)")
+	headingPattern = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedPattern = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	quotePattern   = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	rulePattern    = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_]))\s*([-*_]\s*)+$`)
+	tagPattern     = regexp.MustCompile(`^</?[A-Za-z][A-Za-z0-9-]*(\s[^<>]*)?/?>`)
+	autolinkPatten = regexp.MustCompile(`^<(https?://[^\s<>]+)>`)
+)
+
+// Parse converts Markdown into a Document.
+func Parse(md string) Document {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	md = commentPattern.ReplaceAllString(md, "")
+
+	var doc Document
+	lines := strings.Split(md, "\n")
+	breaks := 0 // Line breaks owed before the next block; runs of blank lines collapse into one
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			breaks = 2
+			continue
+		}
+		if len(doc) > 0 {
+			doc = append(doc, Segment{Text: strings.Repeat("\n", max(breaks, 1))})
+		}
+		breaks = 0
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			doc = append(doc, Segment{Text: strings.Join(code, "\n"), Pre: true, Lang: m[2]})
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			doc = append(doc, parseInline(m[1], Segment{Bold: true})...)
+			continue
+		}
+		if rulePattern.MatchString(line) {
+			doc = append(doc, Segment{Text: "——————"})
+			continue
+		}
+		if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			doc = append(doc, Segment{Text: indent(m[1]) + "• "})
+			doc = append(doc, parseInline(m[2], Segment{})...)
+			continue
+		}
+		if m := orderedPattern.FindStringSubmatch(line); m != nil {
+			doc = append(doc, Segment{Text: indent(m[1]) + m[2] + ". "})
+			doc = append(doc, parseInline(m[3], Segment{})...)
+			continue
+		}
+		if m := quotePattern.FindStringSubmatch(line); m != nil {
+			doc = append(doc, Segment{Text: "│ "})
+			doc = append(doc, parseInline(m[1], Segment{Italic: true})...)
+			continue
+		}
+		doc = append(doc, parseInline(strings.TrimSpace(line), Segment{})...)
+	}
+	return doc
+}
+
+// indent renders nested list levels as two spaces each.
+func indent(leading string) string {
+	width := len(strings.ReplaceAll(leading, "\t", "    "))
+	return strings.Repeat("  ", width/2)
+}
+
+// parseInline splits a line into segments, applying emphasis, code spans and links on top
+// of the formatting in style.
+func parseInline(s string, style Segment) []Segment {
+	var segments []Segment
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			seg := style
+			seg.Text = text.String()
+			segments = append(segments, seg)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			run := countRun(s[i:], '`')
+			if end := strings.Index(s[i+run:], strings.Repeat("`", run)); end >= 0 {
+				flush()
+				seg := style
+				seg.Code = true
+				seg.Text = strings.TrimSpace(s[i+run : i+run+end])
+				segments = append(segments, seg)
+				i += run + end + run
+				continue
+			}
+			text.WriteString(s[i : i+run])
+			i += run
+			continue
+
+		case c == '[' || (c == '!' && i+1 < len(s) && s[i+1] == '['):
+			start := i
+			if c == '!' {
+				start++
+			}
+			if label, href, n := parseLink(s[start:]); n > 0 {
+				flush()
+				if c == '!' && label == "" {
+					label = "image"
+				}
+				linkStyle := style
+				linkStyle.Href = href
+				segments = append(segments, parseInline(label, linkStyle)...)
+				i = start + n
+				continue
+			}
+
+		case c == '<':
+			if m := autolinkPatten.FindStringSubmatch(s[i:]); m != nil {
+				flush()
+				seg := style
+				seg.Text, seg.Href = m[1], m[1]
+				segments = append(segments, seg)
+				i += len(m[0])
+				continue
+			}
+			// Inline HTML is dropped; <br> becomes a line break
+			if m := tagPattern.FindString(s[i:]); m != "" {
+				if strings.HasPrefix(strings.ToLower(m), "<br") {
+					text.WriteByte('\n')
+				}
+				i += len(m)
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			run := countRun(s[i:], c)
+			if run > 3 {
+				break
+			}
+			if c == '~' && run != 2 {
+				break
+			}
+			delim := strings.Repeat(string(c), run)
+			if c == '_' && i > 0 && isWordByte(s[i-1]) {
+				break // snake_case_identifier
+			}
+			end := closingDelimiter(s[i+run:], delim, c)
+			if end < 0 {
+				break
+			}
+			flush()
+			inner := style
+			switch {
+			case c == '~':
+				// Strikethrough is not in Telegram's allowed subset; keep the text
+			case run == 1:
+				inner.Italic = true
+			case run == 2:
+				inner.Bold = true
+			default:
+				inner.Bold, inner.Italic = true, true
+			}
+			segments = append(segments, parseInline(s[i+run:i+run+end], inner)...)
+			i += run + end + run
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		text.WriteString(s[i : i+size])
+		i += size
+	}
+	flush()
+	return segments
+}
+
+// parseLink parses "[label](url)" at the start of s and returns its parts and length. The
+// URL is empty when it is not an absolute http(s) or mailto link.
+func parseLink(s string) (string, string, int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0
+			}
+			end := closingParen(s[i+2:])
+			if end < 0 {
+				return "", "", 0
+			}
+			target := strings.TrimSpace(s[i+2 : i+2+end])
+			// Drop an optional title: [label](url "title")
+			if sp := strings.IndexAny(target, " \t"); sp >= 0 {
+				target = target[:sp]
+			}
+			target = strings.Trim(target, "<>")
+			if !isSafeURL(target) {
+				target = "" // Relative or script links keep only their label
+			}
+			return s[1:i], target, i + 2 + end + 1
+		}
+	}
+	return "", "", 0
+}
+
+// closingParen finds the parenthesis closing a link target, skipping balanced pairs such as
+// those in "https://en.wikipedia.org/wiki/Go_(programming_language)", or returns -1.
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// closingDelimiter finds the end of an emphasis run opened with delim, or -1.
+func closingDelimiter(s, delim string, c byte) int {
+	if s == "" || s[0] == ' ' {
+		return -1 // "* not emphasis"
+	}
+	for from := 0; from < len(s); {
+		idx := strings.Index(s[from:], delim)
+		if idx < 0 {
+			return -1
+		}
+		end := from + idx
+		after := end + len(delim)
+		if end > 0 && s[end-1] != ' ' && (after >= len(s) || s[after] != c) {
+			if c != '_' || after >= len(s) || !isWordByte(s[after]) {
+				return end
+			}
+		}
+		from = end + 1
+	}
+	return -1
+}
+
+func countRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || c == '`' || c == '*' || c == '_' || c == '~' || c == '<' || c == '>' || c == '#' || c == '+' || c == '|'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// isSafeURL accepts the link schemes Telegram opens and relative GitHub links are not.
+func isSafeURL(u string) bool {
+	lower := strings.ToLower(u)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "mailto:")
+}
+
+// HTML renders the document in Telegram's HTML subset, limited to about limit visible
+// characters (0 means unlimited). When text is cut, it ends with "…" and, if readMoreURL is
+// set, a "read more" link to it.
+func (d Document) HTML(limit int, readMoreURL string) string {
+	var b strings.Builder
+	d.render(limit, func(seg Segment) {
+		b.WriteString(open(seg))
+		b.WriteString(html.EscapeString(seg.Text))
+		b.WriteString(closing(seg))
+	}, func() {
+		b.WriteString("…")
+		if readMoreURL != "" {
+			b.WriteString(` <a href="` + html.EscapeString(readMoreURL) + `">read more</a>`)
+		}
+	})
+	return b.String()
+}
+
+// Text renders the document without formatting, limited like HTML.
+func (d Document) Text(limit int) string {
+	var b strings.Builder
+	d.render(limit, func(seg Segment) {
+		b.WriteString(seg.Text)
+	}, func() {
+		b.WriteString("…")
+	})
+	return b.String()
+}
+
+// render emits segments until limit visible runes, cutting the last one at a word boundary.
+func (d Document) render(limit int, emit func(Segment), truncated func()) {
+	used := 0
+	for _, seg := range d {
+		n := utf8.RuneCountInString(seg.Text)
+		if limit <= 0 || used+n <= limit {
+			emit(seg)
+			used += n
+			continue
+		}
+
+		if cut := cutText(seg.Text, limit-used); strings.TrimSpace(cut) != "" {
+			seg.Text = cut
+			emit(seg)
+		}
+		truncated()
+		return
+	}
+}
+
+// cutText shortens s to at most n runes, backing off to the last space or line break when
+// one is reasonably close.
+func cutText(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := runes[:n]
+	for i := len(cut) - 1; i > n*2/3; i-- {
+		if unicode.IsSpace(cut[i]) {
+			return strings.TrimRightFunc(string(cut[:i]), unicode.IsSpace)
+		}
+	}
+	return string(cut)
+}
+
+func open(seg Segment) string {
+	var b strings.Builder
+	if seg.Href != "" {
+		b.WriteString(`<a href="` + html.EscapeString(seg.Href) + `">`)
+	}
+	if seg.Bold {
+		b.WriteString("<b>")
+	}
+	if seg.Italic {
+		b.WriteString("<i>")
+	}
+	switch {
+	case seg.Pre && seg.Lang != "":
+		b.WriteString(`<pre><code class="language-` + html.EscapeString(seg.Lang) + `">`)
+	case seg.Pre:
+		b.WriteString("<pre>")
+	case seg.Code:
+		b.WriteString("<code>")
+	}
+	return b.String()
+}
+
+func closing(seg Segment) string {
+	var b strings.Builder
+	switch {
+	case seg.Pre && seg.Lang != "":
+		b.WriteString("</code></pre>")
+	case seg.Pre:
+		b.WriteString("</pre>")
+	case seg.Code:
+		b.WriteString("</code>")
+	}
+	if seg.Italic {
+		b.WriteString("</i>")
+	}
+	if seg.Bold {
+		b.WriteString("</b>")
+	}
+	if seg.Href != "" {
+		b.WriteString("</a>")
+	}
+	return b.String()
+}
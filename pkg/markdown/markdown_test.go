@@ -0,0 +1,70 @@
+package markdown
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"escaped text", "<3 fixes & more", "&lt;3 fixes &amp; more"},
+		{"safe link", "[docs](https://example.com/?a=1&b=\"2\")", `<a href="https://example.com/?a=1&amp;b=&#34;2&#34;">docs</a>`},
+		{"javascript link", "[docs](javascript:alert(1))", "docs"},
+		{"relative link", "[guide](docs/guide.md)", "guide"},
+		{"balanced parentheses", "[Go](https://en.wikipedia.org/wiki/Go_(language))", `<a href="https://en.wikipedia.org/wiki/Go_(language)">Go</a>`},
+		{"autolink", "<https://x.org>", `<a href="https://x.org">https://x.org</a>`},
+		{"unclosed fence", "```go\nfunc main() {\n  x := a < b", "<pre><code class=\"language-go\">func main() {\n  x := a &lt; b</code></pre>"},
+		{"nested emphasis", "**bold _and italic_ text**", "<b>bold </b><b><i>and italic</i></b><b> text</b>"},
+		{"strong emphasis", "***both***", "<b><i>both</i></b>"},
+		{"snake_case", "call snake_case_name and _real italic_", "call snake_case_name and <i>real italic</i>"},
+		{"comment", "before<!-- hidden\ncomment -->after", "beforeafter"},
+		{"inline", "~~gone~~ `a<b` <br>", "gone <code>a&lt;b</code> \n"},
+		{"blocks", "# Heading\n- item\n  - nested\n1. one\n> _quote_\n---", "<b>Heading</b>\n• item\n  • nested\n1. one\n│ <i>quote</i>\n——————"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.md).HTML(0, ""); got != tt.want {
+				t.Errorf("HTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLTruncation(t *testing.T) {
+	const readMore = ` <a href="https://x.org/r?a=1&amp;b=2">read more</a>`
+	doc := Parse("**Bold words here** and [a link with text](https://example.com) and more text after")
+
+	tests := []struct {
+		limit int
+		want  string
+	}{
+		{5, "<b>Bold</b>…" + readMore},
+		{25, `<b>Bold words here</b> and <a href="https://example.com">a lin</a>…` + readMore},
+		{40, `<b>Bold words here</b> and <a href="https://example.com">a link with text</a> and…` + readMore},
+		{200, `<b>Bold words here</b> and <a href="https://example.com">a link with text</a> and more text after`},
+	}
+	for _, tt := range tests {
+		if got := doc.HTML(tt.limit, "https://x.org/r?a=1&b=2"); got != tt.want {
+			t.Errorf("HTML(%d) = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+
+	code := Parse("```\ncode line\nmore code\n```")
+	if got, want := code.HTML(10, "https://x"), `<pre>code line</pre>… <a href="https://x">read more</a>`; got != want {
+		t.Errorf("HTML(10) of a fence = %q, want %q", got, want)
+	}
+	if got, want := doc.HTML(5, ""), "<b>Bold</b>…"; got != want {
+		t.Errorf("HTML(5) without a URL = %q, want %q", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	doc := Parse("## Fixes\n- **Fixed** [crash](https://example.com) in `parser`")
+	if got, want := doc.Text(0), "Fixes\n• Fixed crash in parser"; got != want {
+		t.Errorf("Text(0) = %q, want %q", got, want)
+	}
+	if got, want := doc.Text(13), "Fixes\n• Fixed…"; got != want {
+		t.Errorf("Text(13) = %q, want %q", got, want)
+	}
+}
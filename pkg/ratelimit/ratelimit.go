@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a rate of events per second with a burst allowance. A zero Rate means unlimited.
+type Limit struct {
+	Rate  float64 // Events per second
+	Burst int     // Events allowed at once; at least 1
+}
+
+// PerSecond returns a Limit of rate events per second with a burst of one second's worth.
+func PerSecond(rate float64) Limit {
+	return Limit{Rate: rate, Burst: int(math.Max(1, math.Ceil(rate)))}
+}
+
+// Unlimited reports whether l lets everything through.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Bucket is a token bucket: it holds up to Burst tokens and refills at Rate per second.
+// It is safe for concurrent use.
+type Bucket struct {
+	limit Limit
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(limit Limit) *Bucket {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &Bucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// Wait blocks until a token is available and takes it, or returns ctx's error. It returns how
+// long it waited.
+func (b *Bucket) Wait(ctx context.Context) (time.Duration, error) {
+	if b.limit.Unlimited() {
+		return 0, nil
+	}
+
+	delay := b.reserve(time.Now())
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		// Hand the token back so that others are not held up by a wait that never happened
+		b.mu.Lock()
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+1)
+		b.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// reserve takes a token, going into debt if there is none, and returns how long until the
+// debt is paid off.
+func (b *Bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// refill adds the tokens earned since the last call. Callers hold b.mu.
+func (b *Bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
+
+// full reports whether the bucket has refilled completely, i.e. it has been idle long enough
+// that dropping it changes nothing.
+func (b *Bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+// sweepInterval is how often Keyed drops idle buckets.
+const sweepInterval = time.Minute
+
+// Keyed holds a Bucket per key, such as one per chat, created on first use. Buckets that are
+// idle are dropped now and then, so keys that are seen once do not pile up.
+type Keyed struct {
+	limit Limit
+
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+}
+
+func NewKeyed(limit Limit) *Keyed {
+	return &Keyed{limit: limit, buckets: make(map[string]*Bucket), lastSweep: time.Now()}
+}
+
+// Wait is Bucket.Wait on the bucket of key.
+func (k *Keyed) Wait(ctx context.Context, key string) (time.Duration, error) {
+	if k.limit.Unlimited() {
+		return 0, nil
+	}
+	return k.bucket(key).Wait(ctx)
+}
+
+func (k *Keyed) bucket(key string) *Bucket {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(k.lastSweep) >= sweepInterval {
+		for key, b := range k.buckets {
+			if b.full(now) {
+				delete(k.buckets, key)
+			}
+		}
+		k.lastSweep = now
+	}
+
+	b, ok := k.buckets[key]
+	if !ok {
+		b = NewBucket(k.limit)
+		k.buckets[key] = b
+	}
+	return b
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPerSecond(t *testing.T) {
+	for rate, burst := range map[float64]int{0.5: 1, 1: 1, 2.5: 3, 30: 30} {
+		if got := PerSecond(rate); got.Burst != burst {
+			t.Errorf("PerSecond(%v).Burst = %d, want %d", rate, got.Burst, burst)
+		}
+	}
+}
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	b := NewBucket(Limit{Rate: 2, Burst: 3})
+	now := b.last
+
+	for i := 0; i < 3; i++ {
+		if delay := b.reserve(now); delay != 0 {
+			t.Fatalf("reserve #%d within the burst = %v, want 0", i+1, delay)
+		}
+	}
+	if delay := b.reserve(now); delay != 500*time.Millisecond {
+		t.Errorf("reserve past the burst = %v, want 500ms", delay)
+	}
+	if delay := b.reserve(now); delay != time.Second {
+		t.Errorf("second reserve past the burst = %v, want 1s", delay)
+	}
+
+	// The debt of two tokens is paid off after a second, and one more is earned by 1.5s
+	now = now.Add(1500 * time.Millisecond)
+	if delay := b.reserve(now); delay != 0 {
+		t.Errorf("reserve after refilling = %v, want 0", delay)
+	}
+	if delay := b.reserve(now); delay != 500*time.Millisecond {
+		t.Errorf("reserve after using the refill = %v, want 500ms", delay)
+	}
+
+	// Refilling stops at the burst
+	now = now.Add(time.Hour)
+	if !b.full(now) || b.tokens != 3 {
+		t.Errorf("tokens after an hour = %v, want 3", b.tokens)
+	}
+}
+
+func TestBucketWaitCancelled(t *testing.T) {
+	b := NewBucket(Limit{Rate: 0.01, Burst: 1})
+	if delay, err := b.Wait(context.Background()); err != nil || delay != 0 {
+		t.Fatalf("first Wait = %v, %v, want no delay", delay, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The token reserved by the cancelled wait was handed back
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 0 {
+		t.Errorf("tokens after a cancelled wait = %v, want no debt", b.tokens)
+	}
+}
+
+func TestBucketWaitDelays(t *testing.T) {
+	b := NewBucket(Limit{Rate: 50, Burst: 1})
+	if _, err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	start := time.Now()
+	delay, err := b.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if delay <= 0 || time.Since(start) < delay {
+		t.Errorf("Wait returned after %v, reporting %v, want a wait of about 20ms", time.Since(start), delay)
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := NewBucket(Limit{})
+	k := NewKeyed(Limit{})
+	for i := 0; i < 100; i++ {
+		if _, err := b.Wait(ctx); err != nil {
+			t.Fatalf("Bucket.Wait: %v", err)
+		}
+		if _, err := k.Wait(ctx, "key"); err != nil {
+			t.Fatalf("Keyed.Wait: %v", err)
+		}
+	}
+}
+
+func TestKeyedSweepsIdleBuckets(t *testing.T) {
+	k := NewKeyed(Limit{Rate: 0.001, Burst: 1})
+	busy, idle := k.bucket("busy"), k.bucket("idle")
+	if busy == idle {
+		t.Fatal("keys share a bucket")
+	}
+	if k.bucket("busy") != busy {
+		t.Fatal("bucket was not reused")
+	}
+
+	// Only the bucket in use is short of tokens when the sweep runs
+	busy.reserve(time.Now())
+	k.lastSweep = time.Now().Add(-sweepInterval)
+	k.bucket("other")
+
+	if _, ok := k.buckets["idle"]; ok {
+		t.Error("idle bucket was kept")
+	}
+	if _, ok := k.buckets["busy"]; !ok {
+		t.Error("busy bucket was dropped")
+	}
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/mackb/releaseradar/pkg/logger"
@@ -22,3 +23,20 @@ func Do(attempts int, sleep time.Duration, fn func() error) error {
 	}
 	return err
 }
+
+// Backoff returns the delay before retrying after the given failed attempt (1 for the first):
+// base doubled for each earlier failure, capped at max, with up to half of it taken off at
+// random so that failures at the same moment are not all retried at the same moment.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay/2)+1))
+}
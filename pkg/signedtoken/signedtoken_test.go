@@ -0,0 +1,59 @@
+package signedtoken
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte{0, 1, 2, 0xff}
+
+	got, err := Verify(secret, Sign(secret, payload))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("Verify = %x, want %x", got, payload)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	secret := []byte("secret")
+	token := Sign(secret, []byte("payload"))
+	encodedPayload, encodedMAC, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name   string
+		secret []byte
+		token  string
+	}{
+		{"wrong secret", []byte("other"), token},
+		{"tampered payload", secret, encode([]byte("pAyload")) + "." + encodedMAC},
+		{"tampered MAC", secret, encodedPayload + "." + encode(bytes.Repeat([]byte{1}, macSize))},
+		{"truncated MAC", secret, token[:len(token)-1]},
+		{"missing MAC", secret, encodedPayload},
+		{"empty MAC", secret, encodedPayload + "."},
+		{"bad encoding", secret, "!!." + encodedMAC},
+		{"empty token", secret, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Verify(tt.secret, tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify(%q) = %v, want ErrInvalidToken", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestEmptySecretNeverVerifies(t *testing.T) {
+	// A token signed with an empty key is a valid HMAC, so Verify must refuse it outright
+	if _, err := Verify(nil, Sign(nil, []byte("payload"))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with an empty secret = %v, want ErrInvalidToken", err)
+	}
+	if _, err := Verify([]byte{}, Sign([]byte{}, []byte("payload"))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with an empty secret = %v, want ErrInvalidToken", err)
+	}
+}
@@ -12,5 +12,5 @@ ON CONFLICT (id) DO NOTHING;
 
 -- Insert a dummy subscription for the user to the repository
 INSERT INTO subscriptions (id, repo_id, user_id, channel, created_at, updated_at) VALUES
-    ('c2def102-abcd-efab-cdef-123456789012', 'b1cde0f1-1234-5678-90ab-cdef01234567', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'some_telegram_chat_id', NOW(), NOW())
+    ('c2def102-abcd-efab-cdef-123456789012', 'b1cde0f1-1234-5678-90ab-cdef01234567', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11', 'telegram:123456789', NOW(), NOW())
 ON CONFLICT (id) DO NOTHING;